@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/quasilyte/gophers-and-dragons/wasm/program"
+	"github.com/quasilyte/gophers-and-dragons/wasm/sim"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func main() {
+	tacticPath := flag.String("tactic", "", "the tactic Go source file")
+	savePath := flag.String("save", "campaign.json", "the campaign progress file")
+	avatarHP := flag.Int("hp", 40, "avatar max HP")
+	avatarMP := flag.Int("mp", 20, "avatar max MP")
+	seed := flag.Int64("seed", 0, "random seed; 0 means current time")
+	flag.Parse()
+
+	if *tacticPath == "" {
+		log.Fatal("-tactic argument is required")
+	}
+	code, err := ioutil.ReadFile(*tacticPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tactic, err := program.LoadTactic(string(code))
+	if err != nil {
+		log.Fatalf("load tactic: %v", err)
+	}
+
+	// Missing progress file means that the campaign is not started yet.
+	data, err := ioutil.ReadFile(*savePath)
+	if err != nil && !os.IsNotExist(err) {
+		log.Fatal(err)
+	}
+	progress, err := sim.ParseCampaignProgress(data)
+	if err != nil {
+		log.Fatalf("%s: %v", *savePath, err)
+	}
+
+	config := &sim.Config{
+		AvatarHP: *avatarHP,
+		AvatarMP: *avatarMP,
+		Seed:     *seed,
+	}
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+
+	for _, a := range sim.RunCampaign(config, progress, tactic) {
+		switch a := a.(type) {
+		case simstep.Log:
+			fmt.Println(a.Message)
+		case simstep.RedLog:
+			fmt.Println(a.Message)
+		case simstep.GreenLog:
+			fmt.Println(a.Message)
+		case simstep.SaveProgress:
+			if err := ioutil.WriteFile(*savePath, []byte(a.Data), 0644); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,530 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quasilyte/gophers-and-dragons/wasm/program"
+	"github.com/quasilyte/gophers-and-dragons/wasm/sim"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// hardcoreConfig is a fixed ruleset for all hardcore attempts.
+var hardcoreConfig = sim.Config{
+	AvatarHP: 40,
+	AvatarMP: 20,
+	Rounds:   10,
+}
+
+// hardcoreTimeout limits the attempt evaluation time.
+// Timed out attempts are recorded as failed.
+var hardcoreTimeout = 5 * time.Second
+
+// hardcoreWorkerEnv makes the server binary run a single attempt evaluation.
+// Attempts are evaluated in a subprocess that is killed on timeout,
+// since the tactic interpreter can't be interrupted.
+const hardcoreWorkerEnv = "GND_HARDCORE_WORKER"
+
+// hardcoreSeedDuration is how long a seed accepts attempts after it's issued.
+// The seed is revealed only after that, so it can't be shared with other players.
+var hardcoreSeedDuration = 24 * time.Hour
+
+// hardcoreMaxWorkers limits the number of attempts that are evaluated at the same time.
+// Other attempts wait for a free worker.
+var hardcoreMaxWorkers = runtime.NumCPU()
+
+// hardcoreMaxRequestSize limits the API request body size.
+const hardcoreMaxRequestSize = 1 << 20
+
+type hardcoreJob struct {
+	Code string `json:"code"`
+	Seed int64  `json:"seed"`
+}
+
+type hardcoreOutcome struct {
+	Score   int             `json:"score"`
+	Victory bool            `json:"victory"`
+	Error   string          `json:"error"`
+	Actions [][]interface{} `json:"actions"`
+}
+
+// hardcoreRecord is an append-only ladder log entry.
+// Records are never modified or removed once written.
+type hardcoreRecord struct {
+	Kind   string `json:"kind"` // "seed", "token", "start" or "result"
+	SeedID int    `json:"seedID"`
+	Seed   int64  `json:"seed"`
+	Player string `json:"player,omitempty"`
+	Time   int64  `json:"time"`
+
+	// Token is a hash of the attempt token.
+	// Tokens themselves are never stored.
+	Token string `json:"token,omitempty"`
+
+	Score   int    `json:"score,omitempty"`
+	Victory bool   `json:"victory,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// hardcoreLadder issues secret seeds and allows one attempt per player for each of them.
+//
+// Players are registered by the server operator, see addHardcorePlayer.
+// Every attempt needs a server-issued token that is bound to the seed and the player.
+// A player gets only one token per seed and the token is consumed by the attempt.
+type hardcoreLadder struct {
+	mu   sync.Mutex
+	file *os.File
+
+	// players maps a player name to its key hash.
+	players map[string]string
+
+	// workers is a semaphore for the attempt evaluation workers.
+	workers chan struct{}
+
+	seeds   []hardcoreSeed
+	issued  map[hardcoreAttemptKey]bool
+	tokens  map[string]hardcoreAttemptKey
+	results map[int][]hardcoreRecord
+}
+
+type hardcoreSeed struct {
+	value  int64
+	issued time.Time
+}
+
+// isOpen reports whether the seed still accepts attempts.
+func (seed hardcoreSeed) isOpen() bool {
+	return time.Since(seed.issued) < hardcoreSeedDuration
+}
+
+// hardcoreResult is a public part of the result record.
+type hardcoreResult struct {
+	Player  string `json:"player"`
+	Score   int    `json:"score"`
+	Victory bool   `json:"victory"`
+	Error   string `json:"error,omitempty"`
+	Time    int64  `json:"time"`
+}
+
+type hardcoreAttemptKey struct {
+	seedID int
+	player string
+}
+
+func openHardcoreLadder(filename string, players map[string]string) (*hardcoreLadder, error) {
+	f, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	l := &hardcoreLadder{
+		file:    f,
+		players: players,
+		workers: make(chan struct{}, hardcoreMaxWorkers),
+		issued:  make(map[hardcoreAttemptKey]bool),
+		tokens:  make(map[string]hardcoreAttemptKey),
+		results: make(map[int][]hardcoreRecord),
+	}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var rec hardcoreRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+		l.apply(rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *hardcoreLadder) apply(rec hardcoreRecord) {
+	switch rec.Kind {
+	case "seed":
+		l.seeds = append(l.seeds, hardcoreSeed{value: rec.Seed, issued: time.Unix(rec.Time, 0)})
+	case "token":
+		key := hardcoreAttemptKey{rec.SeedID, rec.Player}
+		l.issued[key] = true
+		l.tokens[rec.Token] = key
+	case "start":
+		// An attempt that was started but has no result still counts.
+		l.issued[hardcoreAttemptKey{rec.SeedID, rec.Player}] = true
+		delete(l.tokens, rec.Token)
+	case "result":
+		l.results[rec.SeedID] = append(l.results[rec.SeedID], rec)
+	}
+}
+
+func (l *hardcoreLadder) record(rec hardcoreRecord) error {
+	rec.Time = time.Now().Unix()
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.apply(rec)
+	return nil
+}
+
+// issueSeed creates a new secret seed and returns its ID.
+func (l *hardcoreLadder) issueSeed() (int, error) {
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		return 0, err
+	}
+	seed := int64(binary.LittleEndian.Uint64(buf[:]) >> 1)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	id := len(l.seeds)
+	return id, l.record(hardcoreRecord{Kind: "seed", SeedID: id, Seed: seed})
+}
+
+// issueToken creates a single-use attempt token for the player.
+func (l *hardcoreLadder) issueToken(seedID int, player string) (string, error) {
+	token, err := newHardcoreSecret()
+	if err != nil {
+		return "", err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if seedID < 0 || seedID >= len(l.seeds) {
+		return "", errors.New("unknown seed")
+	}
+	if !l.seeds[seedID].isOpen() {
+		return "", errors.New("this seed is closed")
+	}
+	if l.issued[hardcoreAttemptKey{seedID, player}] {
+		return "", errors.New("this seed was already attempted")
+	}
+	rec := hardcoreRecord{Kind: "token", SeedID: seedID, Player: player, Token: hashToken(token)}
+	return token, l.record(rec)
+}
+
+// startAttempt consumes the token, records the attempt start and returns the seed.
+func (l *hardcoreLadder) startAttempt(seedID int, token string) (int64, string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hash := hashToken(token)
+	key, ok := l.tokens[hash]
+	if !ok || key.seedID != seedID {
+		return 0, "", errors.New("invalid or already used token")
+	}
+	if !l.seeds[seedID].isOpen() {
+		// The seed can be already revealed.
+		return 0, "", errors.New("this seed is closed")
+	}
+	err := l.record(hardcoreRecord{Kind: "start", SeedID: seedID, Player: key.player, Token: hash})
+	return l.seeds[seedID].value, key.player, err
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newHardcoreSecret returns a random hex string for the tokens and player keys.
+func newHardcoreSecret() (string, error) {
+	var buf [16]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf[:]), nil
+}
+
+// loadHardcorePlayers reads the players file.
+// Every line contains a player name and its key hash separated by a space.
+func loadHardcorePlayers(filename string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	players := make(map[string]string)
+	for i, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a name and a key hash", filename, i+1)
+		}
+		players[fields[0]] = fields[1]
+	}
+	return players, nil
+}
+
+// addHardcorePlayer registers a new player in the players file and returns its key.
+// Only the key hash is stored.
+func addHardcorePlayer(filename, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, " \t\n:") {
+		return "", fmt.Errorf("invalid player name %q", name)
+	}
+	players, err := loadHardcorePlayers(filename)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := players[name]; ok {
+		return "", fmt.Errorf("player %q already exists", name)
+	}
+	key, err := newHardcoreSecret()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return "", err
+	}
+	if _, err := fmt.Fprintf(f, "%s %s\n", name, hashToken(key)); err != nil {
+		f.Close()
+		return "", err
+	}
+	return key, f.Close()
+}
+
+// authenticate returns the player name from the request basic auth credentials.
+func (l *hardcoreLadder) authenticate(r *http.Request) (string, error) {
+	name, key, ok := r.BasicAuth()
+	if !ok {
+		return "", errors.New("player credentials are required")
+	}
+	hash, ok := l.players[name]
+	if !ok || subtle.ConstantTimeCompare([]byte(hash), []byte(hashToken(key))) != 1 {
+		return "", errors.New("invalid player credentials")
+	}
+	return name, nil
+}
+
+func (l *hardcoreLadder) finishAttempt(rec hardcoreRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rec.Kind = "result"
+	return l.record(rec)
+}
+
+func (l *hardcoreLadder) seedResults(seedID int) []hardcoreRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]hardcoreRecord(nil), l.results[seedID]...)
+}
+
+// revealSeed returns the seed if it no longer accepts attempts.
+func (l *hardcoreLadder) revealSeed(seedID int) (int64, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if seedID < 0 || seedID >= len(l.seeds) || l.seeds[seedID].isOpen() {
+		return 0, false
+	}
+	return l.seeds[seedID].value, true
+}
+
+func (l *hardcoreLadder) handleSeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST expected", http.StatusMethodNotAllowed)
+		return
+	}
+	id, err := l.issueSeed()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"seedID": id})
+}
+
+func (l *hardcoreLadder) handleToken(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST expected", http.StatusMethodNotAllowed)
+		return
+	}
+	player, err := l.authenticate(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+	var req struct {
+		SeedID int `json:"seedID"`
+	}
+	body := http.MaxBytesReader(w, r.Body, hardcoreMaxRequestSize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	token, err := l.issueToken(req.SeedID, player)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	writeJSON(w, map[string]interface{}{"token": token})
+}
+
+func (l *hardcoreLadder) handleAttempt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST expected", http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		SeedID int    `json:"seedID"`
+		Token  string `json:"token"`
+		Code   string `json:"code"`
+	}
+	body := http.MaxBytesReader(w, r.Body, hardcoreMaxRequestSize)
+	if err := json.NewDecoder(body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// The token is not consumed until there is a free worker.
+	select {
+	case l.workers <- struct{}{}:
+		defer func() { <-l.workers }()
+	case <-r.Context().Done():
+		http.Error(w, "the server is busy", http.StatusServiceUnavailable)
+		return
+	}
+
+	seed, player, err := l.startAttempt(req.SeedID, req.Token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	outcome := runHardcoreAttempt(req.Code, seed)
+	rec := hardcoreRecord{
+		SeedID:  req.SeedID,
+		Player:  player,
+		Score:   outcome.Score,
+		Victory: outcome.Victory,
+		Error:   outcome.Error,
+	}
+	if err := l.finishAttempt(rec); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The seed is not revealed here, it's still open for other players.
+	writeJSON(w, map[string]interface{}{
+		"score":   outcome.Score,
+		"victory": outcome.Victory,
+		"error":   outcome.Error,
+		"actions": outcome.Actions,
+	})
+}
+
+func (l *hardcoreLadder) handleLadder(w http.ResponseWriter, r *http.Request) {
+	var seedID int
+	if _, err := fmt.Sscan(r.URL.Query().Get("seedID"), &seedID); err != nil {
+		http.Error(w, "bad seedID", http.StatusBadRequest)
+		return
+	}
+	records := l.seedResults(seedID)
+	results := make([]hardcoreResult, len(records))
+	for i, rec := range records {
+		results[i] = hardcoreResult{
+			Player:  rec.Player,
+			Score:   rec.Score,
+			Victory: rec.Victory,
+			Error:   rec.Error,
+			Time:    rec.Time,
+		}
+	}
+	resp := map[string]interface{}{"seedID": seedID, "results": results}
+	if seed, ok := l.revealSeed(seedID); ok {
+		resp["seed"] = seed
+	}
+	writeJSON(w, resp)
+}
+
+// runHardcoreAttempt evaluates the attempt in a worker subprocess.
+func runHardcoreAttempt(code string, seed int64) hardcoreOutcome {
+	job, err := json.Marshal(hardcoreJob{Code: code, Seed: seed})
+	if err != nil {
+		return hardcoreOutcome{Error: err.Error()}
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return hardcoreOutcome{Error: err.Error()}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), hardcoreTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, self)
+	cmd.Env = append(os.Environ(), hardcoreWorkerEnv+"=1")
+	cmd.Stdin = bytes.NewReader(job)
+	out, err := cmd.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return hardcoreOutcome{Error: "evaluation timeout"}
+	}
+	if err != nil {
+		return hardcoreOutcome{Error: "evaluation failed: " + err.Error()}
+	}
+	var outcome hardcoreOutcome
+	if err := json.Unmarshal(out, &outcome); err != nil {
+		return hardcoreOutcome{Error: "evaluation failed: " + err.Error()}
+	}
+	return outcome
+}
+
+// runHardcoreWorker reads a hardcoreJob from r, evaluates it and writes a hardcoreOutcome to w.
+func runHardcoreWorker(r io.Reader, w io.Writer) error {
+	var job hardcoreJob
+	if err := json.NewDecoder(r).Decode(&job); err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(evalHardcoreJob(job))
+}
+
+func evalHardcoreJob(job hardcoreJob) hardcoreOutcome {
+	tactic, err := program.LoadTactic(job.Code)
+	if err != nil {
+		return hardcoreOutcome{Error: err.Error()}
+	}
+
+	config := hardcoreConfig
+	config.Seed = job.Seed
+	actions := sim.RunTactic(&config, tactic)
+
+	var outcome hardcoreOutcome
+	outcome.Actions = make([][]interface{}, len(actions))
+	for i, a := range actions {
+		outcome.Actions[i] = a.Fields()
+		switch a := a.(type) {
+		case simstep.UpdateScore:
+			outcome.Score += a.Delta
+		case simstep.Victory:
+			outcome.Victory = true
+		}
+	}
+	return outcome
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,368 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testTactic = `package tactic
+
+import "github.com/quasilyte/gophers-and-dragons/game"
+
+func ChooseCard(s game.State) game.CardType {
+	return game.CardAttack
+}
+`
+
+const testLoopTactic = `package tactic
+
+import "github.com/quasilyte/gophers-and-dragons/game"
+
+func ChooseCard(s game.State) game.CardType {
+	for {
+	}
+}
+`
+
+func TestMain(m *testing.M) {
+	// Attempts re-execute the current binary as a worker.
+	if os.Getenv(hardcoreWorkerEnv) != "" {
+		if err := runHardcoreWorker(os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testPlayerKeys maps the registered test players to their keys.
+var testPlayerKeys = map[string]string{
+	"gopher": "gopher-key",
+	"dragon": "dragon-key",
+}
+
+func openTestLadder(t *testing.T, filename string) *hardcoreLadder {
+	t.Helper()
+	players := make(map[string]string)
+	for name, key := range testPlayerKeys {
+		players[name] = hashToken(key)
+	}
+	l, err := openHardcoreLadder(filename, players)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.file.Close() })
+	return l
+}
+
+func postJSON(t *testing.T, handler http.HandlerFunc, req interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	return postJSONAs(t, handler, "", "", req)
+}
+
+// postJSONAs sends a request with the player credentials.
+// An empty player name means no credentials.
+func postJSONAs(t *testing.T, handler http.HandlerFunc, player, key string, req interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data))
+	if player != "" {
+		r.SetBasicAuth(player, key)
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func requestTestToken(t *testing.T, l *hardcoreLadder, seedID int, player string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := map[string]interface{}{"seedID": seedID}
+	return postJSONAs(t, l.handleToken, player, testPlayerKeys[player], req)
+}
+
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder, dst interface{}) {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", w.Code, w.Body.String())
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), dst); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func issueTestSeed(t *testing.T, l *hardcoreLadder) int {
+	t.Helper()
+	var resp struct {
+		SeedID int `json:"seedID"`
+	}
+	decodeResponse(t, postJSON(t, l.handleSeed, nil), &resp)
+	return resp.SeedID
+}
+
+func issueTestToken(t *testing.T, l *hardcoreLadder, seedID int, player string) string {
+	t.Helper()
+	var resp struct {
+		Token string `json:"token"`
+	}
+	decodeResponse(t, requestTestToken(t, l, seedID, player), &resp)
+	return resp.Token
+}
+
+func TestHardcoreIssueSeed(t *testing.T) {
+	l := openTestLadder(t, filepath.Join(t.TempDir(), "hardcore.jsonl"))
+
+	for i := 0; i < 3; i++ {
+		if id := issueTestSeed(t, l); id != i {
+			t.Fatalf("seed %d: got ID %d", i, id)
+		}
+	}
+	if len(l.seeds) != 3 {
+		t.Fatalf("got %d seeds, want 3", len(l.seeds))
+	}
+
+	if w := requestTestToken(t, l, 3, "gopher"); w.Code != http.StatusForbidden {
+		t.Fatalf("token for an unknown seed: status %d", w.Code)
+	}
+}
+
+func TestHardcoreAuthentication(t *testing.T) {
+	l := openTestLadder(t, filepath.Join(t.TempDir(), "hardcore.jsonl"))
+	seedID := issueTestSeed(t, l)
+	req := map[string]interface{}{"seedID": seedID}
+
+	tests := []struct {
+		player string
+		key    string
+	}{
+		{"", ""},
+		{"gopher", ""},
+		{"gopher", "dragon-key"},
+		{"stranger", "stranger-key"},
+	}
+	for _, test := range tests {
+		w := postJSONAs(t, l.handleToken, test.player, test.key, req)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("player=%q key=%q: status %d", test.player, test.key, w.Code)
+		}
+	}
+	if w := requestTestToken(t, l, seedID, "gopher"); w.Code != http.StatusOK {
+		t.Fatalf("registered player: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHardcorePlayers(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "players.txt")
+
+	key, err := addHardcorePlayer(filename, "gopher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := addHardcorePlayer(filename, "gopher"); err == nil {
+		t.Fatal("a player is registered twice")
+	}
+	if _, err := addHardcorePlayer(filename, "bad name"); err == nil {
+		t.Fatal("a name with a space is accepted")
+	}
+
+	players, err := loadHardcorePlayers(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(players) != 1 || players["gopher"] != hashToken(key) {
+		t.Fatalf("loaded players %v, want gopher with the key hash", players)
+	}
+}
+
+func TestHardcoreSecondAttempt(t *testing.T) {
+	l := openTestLadder(t, filepath.Join(t.TempDir(), "hardcore.jsonl"))
+	seedID := issueTestSeed(t, l)
+	token := issueTestToken(t, l, seedID, "gopher")
+
+	var result struct {
+		Seed    *int64            `json:"seed"`
+		Error   string            `json:"error"`
+		Actions []json.RawMessage `json:"actions"`
+	}
+	attempt := map[string]interface{}{"seedID": seedID, "token": token, "code": testTactic}
+	decodeResponse(t, postJSON(t, l.handleAttempt, attempt), &result)
+	if result.Error != "" {
+		t.Fatalf("attempt failed: %s", result.Error)
+	}
+	if result.Seed != nil {
+		t.Fatalf("open seed is revealed")
+	}
+	if len(result.Actions) == 0 {
+		t.Fatal("no actions returned")
+	}
+
+	// The token is consumed by the first attempt.
+	if w := postJSON(t, l.handleAttempt, attempt); w.Code != http.StatusForbidden {
+		t.Fatalf("reused token: status %d", w.Code)
+	}
+	// And the player can't get another one for the same seed.
+	if w := requestTestToken(t, l, seedID, "gopher"); w.Code != http.StatusForbidden {
+		t.Fatalf("second token: status %d", w.Code)
+	}
+	// Tokens are bound to their seeds.
+	otherSeedID := issueTestSeed(t, l)
+	otherToken := issueTestToken(t, l, otherSeedID, "gopher")
+	attempt = map[string]interface{}{"seedID": seedID, "token": otherToken, "code": testTactic}
+	if w := postJSON(t, l.handleAttempt, attempt); w.Code != http.StatusForbidden {
+		t.Fatalf("token for another seed: status %d", w.Code)
+	}
+}
+
+func TestHardcoreSeedReveal(t *testing.T) {
+	defer func(duration time.Duration) { hardcoreSeedDuration = duration }(hardcoreSeedDuration)
+
+	l := openTestLadder(t, filepath.Join(t.TempDir(), "hardcore.jsonl"))
+	seedID := issueTestSeed(t, l)
+	token := issueTestToken(t, l, seedID, "gopher")
+	unusedToken := issueTestToken(t, l, seedID, "dragon")
+	attempt := map[string]interface{}{"seedID": seedID, "token": token, "code": testTactic}
+	decodeResponse(t, postJSON(t, l.handleAttempt, attempt), &struct{}{})
+
+	getLadder := func() (seed *int64, results int) {
+		var resp struct {
+			Seed    *int64            `json:"seed"`
+			Results []json.RawMessage `json:"results"`
+		}
+		w := httptest.NewRecorder()
+		l.handleLadder(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/?seedID=%d", seedID), nil))
+		decodeResponse(t, w, &resp)
+		return resp.Seed, len(resp.Results)
+	}
+
+	if seed, results := getLadder(); seed != nil || results != 1 {
+		t.Fatalf("open seed ladder: seed=%v results=%d", seed, results)
+	}
+
+	// Close the seed.
+	hardcoreSeedDuration = 0
+	seed, _ := getLadder()
+	if seed == nil || *seed != l.seeds[seedID].value {
+		t.Fatalf("closed seed is not revealed: %v", seed)
+	}
+	if w := requestTestToken(t, l, seedID, "gopher"); w.Code != http.StatusForbidden {
+		t.Fatalf("token for a closed seed: status %d", w.Code)
+	}
+	attempt = map[string]interface{}{"seedID": seedID, "token": unusedToken, "code": testTactic}
+	if w := postJSON(t, l.handleAttempt, attempt); w.Code != http.StatusForbidden {
+		t.Fatalf("attempt for a closed seed: status %d", w.Code)
+	}
+}
+
+func TestHardcoreWorkers(t *testing.T) {
+	l := openTestLadder(t, filepath.Join(t.TempDir(), "hardcore.jsonl"))
+	seedID := issueTestSeed(t, l)
+	token := issueTestToken(t, l, seedID, "gopher")
+
+	// Occupy all workers.
+	for i := 0; i < cap(l.workers); i++ {
+		l.workers <- struct{}{}
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"seedID": seedID, "token": token, "code": testTactic})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	w := httptest.NewRecorder()
+	l.handleAttempt(w, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(data)).WithContext(ctx))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("attempt without a free worker: status %d", w.Code)
+	}
+
+	// The token is still valid when a worker is free.
+	<-l.workers
+	attempt := map[string]interface{}{"seedID": seedID, "token": token, "code": testTactic}
+	if w := postJSON(t, l.handleAttempt, attempt); w.Code != http.StatusOK {
+		t.Fatalf("attempt with a free worker: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHardcoreRequestSize(t *testing.T) {
+	l := openTestLadder(t, filepath.Join(t.TempDir(), "hardcore.jsonl"))
+	seedID := issueTestSeed(t, l)
+	token := issueTestToken(t, l, seedID, "gopher")
+
+	code := strings.Repeat(" ", hardcoreMaxRequestSize)
+	attempt := map[string]interface{}{"seedID": seedID, "token": token, "code": code}
+	if w := postJSON(t, l.handleAttempt, attempt); w.Code != http.StatusBadRequest {
+		t.Fatalf("oversized request: status %d", w.Code)
+	}
+}
+
+func TestHardcoreReload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "hardcore.jsonl")
+	l := openTestLadder(t, filename)
+	seedID := issueTestSeed(t, l)
+	token := issueTestToken(t, l, seedID, "gopher")
+	unusedToken := issueTestToken(t, l, seedID, "dragon")
+	attempt := map[string]interface{}{"seedID": seedID, "token": token, "code": testTactic}
+	var result struct {
+		Score int `json:"score"`
+	}
+	decodeResponse(t, postJSON(t, l.handleAttempt, attempt), &result)
+
+	reloaded := openTestLadder(t, filename)
+	if len(reloaded.seeds) != 1 || reloaded.seeds[0].value != l.seeds[0].value {
+		t.Fatalf("reloaded seeds %v, want %v", reloaded.seeds, l.seeds)
+	}
+	results := reloaded.seedResults(seedID)
+	if len(results) != 1 {
+		t.Fatalf("got %d results, want 1", len(results))
+	}
+	if results[0].Player != "gopher" || results[0].Score != result.Score {
+		t.Fatalf("reloaded result %+v, want gopher with score %d", results[0], result.Score)
+	}
+
+	// The used token stays used and the unused one is still valid.
+	if w := postJSON(t, reloaded.handleAttempt, attempt); w.Code != http.StatusForbidden {
+		t.Fatalf("reused token after reload: status %d", w.Code)
+	}
+	attempt = map[string]interface{}{"seedID": seedID, "token": unusedToken, "code": testTactic}
+	if w := postJSON(t, reloaded.handleAttempt, attempt); w.Code != http.StatusOK {
+		t.Fatalf("unused token after reload: status %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestHardcoreTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { hardcoreTimeout = timeout }(hardcoreTimeout)
+	hardcoreTimeout = 500 * time.Millisecond
+
+	l := openTestLadder(t, filepath.Join(t.TempDir(), "hardcore.jsonl"))
+	seedID := issueTestSeed(t, l)
+	token := issueTestToken(t, l, seedID, "gopher")
+
+	start := time.Now()
+	var result struct {
+		Error string `json:"error"`
+	}
+	attempt := map[string]interface{}{"seedID": seedID, "token": token, "code": testLoopTactic}
+	decodeResponse(t, postJSON(t, l.handleAttempt, attempt), &result)
+	if result.Error != "evaluation timeout" {
+		t.Fatalf("got error %q, want evaluation timeout", result.Error)
+	}
+	if elapsed := time.Since(start); elapsed > 10*hardcoreTimeout {
+		t.Fatalf("attempt took %v", elapsed)
+	}
+	results := l.seedResults(seedID)
+	if len(results) != 1 || results[0].Error != "evaluation timeout" {
+		t.Fatalf("timed out attempt is not recorded: %+v", results)
+	}
+}
@@ -2,17 +2,53 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
 )
 
 func main() {
+	if os.Getenv(hardcoreWorkerEnv) != "" {
+		if err := runHardcoreWorker(os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	port := flag.String("p", "8080", "port to serve on")
 	directory := flag.String("d", "./www", "the directory of static file to host")
+	hardcoreLog := flag.String("hardcore-log", "", "the hardcore ladder records file; empty disables the hardcore mode")
+	hardcorePlayers := flag.String("hardcore-players", "hardcore-players.txt", "the hardcore ladder players file")
+	addPlayer := flag.String("hardcore-add-player", "", "register a hardcore ladder player, print its key and exit")
 	flag.Parse()
 
+	if *addPlayer != "" {
+		key, err := addHardcorePlayer(*hardcorePlayers, *addPlayer)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(key)
+		return
+	}
+
 	http.Handle("/", http.FileServer(http.Dir(*directory)))
 
+	if *hardcoreLog != "" {
+		players, err := loadHardcorePlayers(*hardcorePlayers)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ladder, err := openHardcoreLadder(*hardcoreLog, players)
+		if err != nil {
+			log.Fatal(err)
+		}
+		http.HandleFunc("/api/hardcore/seed", ladder.handleSeed)
+		http.HandleFunc("/api/hardcore/token", ladder.handleToken)
+		http.HandleFunc("/api/hardcore/attempt", ladder.handleAttempt)
+		http.HandleFunc("/api/hardcore/ladder", ladder.handleLadder)
+	}
+
 	log.Printf("Serving %s on HTTP port: %s\n", *directory, *port)
 	log.Fatal(http.ListenAndServe(":"+*port, nil))
 }
@@ -17,11 +17,21 @@ func _() {
 	_ = x[CardStun-6]
 	_ = x[CardHeal-7]
 	_ = x[CardParry-8]
+	_ = x[CardShieldBash-9]
+	_ = x[CardChainLightning-10]
+	_ = x[CardBackstab-11]
+	_ = x[CardBlock-12]
+	_ = x[CardDrain-13]
+	_ = x[CardPoison-14]
+	_ = x[CardFocus-15]
+	_ = x[CardMeditate-16]
+	_ = x[CardSmokeBomb-17]
+	_ = x[CardScrollOfInsight-18]
 }
 
-const _CardType_name = "AttackMagicArrowRetreatRestPowerAttackFireboltStunHealParry"
+const _CardType_name = "AttackMagicArrowRetreatRestPowerAttackFireboltStunHealParryShieldBashChainLightningBackstabBlockDrainPoisonFocusMeditateSmokeBombScrollOfInsight"
 
-var _CardType_index = [...]uint8{0, 6, 16, 23, 27, 38, 46, 50, 54, 59}
+var _CardType_index = [...]uint8{0, 6, 16, 23, 27, 38, 46, 50, 54, 59, 69, 83, 91, 96, 101, 107, 112, 120, 129, 144}
 
 func (i CardType) String() string {
 	if i < 0 || i >= CardType(len(_CardType_index)-1) {
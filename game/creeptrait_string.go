@@ -13,11 +13,17 @@ func _() {
 	_ = x[TraitWeakToFire-2]
 	_ = x[TraitSlow-3]
 	_ = x[TraitRanged-4]
+	_ = x[TraitRegeneration-5]
+	_ = x[TraitThorns-6]
+	_ = x[TraitLifesteal-7]
+	_ = x[TraitSplit-8]
+	_ = x[TraitIncorporeal-9]
+	_ = x[TraitFast-10]
 }
 
-const _CreepTrait_name = "CowardMagicImmunityWeakToFireSlowRanged"
+const _CreepTrait_name = "CowardMagicImmunityWeakToFireSlowRangedRegenerationThornsLifestealSplitIncorporealFast"
 
-var _CreepTrait_index = [...]uint8{0, 6, 19, 29, 33, 39}
+var _CreepTrait_index = [...]uint8{0, 6, 19, 29, 33, 39, 51, 57, 66, 71, 82, 86}
 
 func (i CreepTrait) String() string {
 	if i < 0 || i >= CreepTrait(len(_CreepTrait_index)-1) {
@@ -15,11 +15,16 @@ func _() {
 	_ = x[CreepFairy-4]
 	_ = x[CreepMummy-5]
 	_ = x[CreepDragon-6]
+	_ = x[CreepTroll-7]
+	_ = x[CreepGolem-8]
+	_ = x[CreepVampire-9]
+	_ = x[CreepSlime-10]
+	_ = x[CreepGhost-11]
 }
 
-const _CreepType_name = "NoneCheepyImpLionFairyMummyDragon"
+const _CreepType_name = "NoneCheepyImpLionFairyMummyDragonTrollGolemVampireSlimeGhost"
 
-var _CreepType_index = [...]uint8{0, 4, 10, 13, 17, 22, 27, 33}
+var _CreepType_index = [...]uint8{0, 4, 10, 13, 17, 22, 27, 33, 38, 43, 50, 55, 60}
 
 func (i CreepType) String() string {
 	if i < 0 || i >= CreepType(len(_CreepType_index)-1) {
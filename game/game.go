@@ -24,14 +24,42 @@ type State struct {
 	// Creep is an information about your current opponent.
 	Creep Creep
 
+	// Opponent is an information about the enemy avatar.
+	// It's only used in the duel mode; there are no creeps in duels.
+	Opponent Avatar
+
 	// NextCreep is a type of the next creep.
 	// Next creep is encountered after the current creep is defeated.
 	// If there is no next creep, a special type CreepNone indicates that.
 	NextCreep CreepType
 
+	// Forecast is a list of creeps that are encountered after the NextCreep.
+	// It's only filled by the ScrollOfInsight card.
+	Forecast []CreepType
+
+	// LastCards contains the cards played during the current round.
+	// The most recent card is the last one.
+	// Only a few last cards are kept, enough to track the card combos.
+	LastCards []CardType
+
 	// Deck is your cards collection.
 	// It's keyed by a card type, like CardAttack.
 	Deck map[CardType]Card
+
+	// Potions is your consumables inventory.
+	// Unlike cards, every potion can be used only once.
+	// Potions are only dropped when they're enabled in the game settings.
+	Potions []Potion
+}
+
+// HasPotion reports whether the inventory contains a potion of the specified type.
+func (st *State) HasPotion(typ PotionType) bool {
+	for _, p := range st.Potions {
+		if p.Type == typ {
+			return true
+		}
+	}
+	return false
 }
 
 // Can reports whether it's legal to do a cardType move.
@@ -55,6 +83,19 @@ type Creep struct {
 	// You probably want to use Creep.IsStunned() instead of this.
 	Stun int
 
+	// Intent is an action this creep is going to perform during its next move.
+	Intent CreepIntent
+
+	// Enraged creeps deal 50% more damage.
+	Enraged bool
+
+	// Minions is a number of summoned minions.
+	// Every minion deals some extra damage during the creep move.
+	Minions int
+
+	// Poison is a number of turns this creep is going to take the poison damage.
+	Poison int
+
 	CreepStats
 }
 
@@ -64,6 +105,30 @@ func (c *Creep) IsFull() bool { return c.HP == c.MaxHP }
 // IsStunned reports whether creep is currently stunned.
 func (c *Creep) IsStunned() bool { return c.Stun > 0 }
 
+// ResistDamage returns the damage left after the creep element resistance is applied.
+func (c *Creep) ResistDamage(e Element, damage int) int {
+	damage -= damage * c.Resistances[e] / 100
+	if damage < 0 {
+		return 0
+	}
+	return damage
+}
+
+// EffectiveDamage returns the damage this creep would take from the element.
+// Unlike ResistDamage, it also takes the Defend intent into account.
+//
+// For the card damage, use the card Element and a value from its Power range:
+//
+//	card := s.Deck[game.CardFirebolt]
+//	dmg := s.Creep.EffectiveDamage(card.Element, card.Power.Low())
+func (c *Creep) EffectiveDamage(e Element, damage int) int {
+	damage = c.ResistDamage(e, damage)
+	if c.Intent == IntentDefend && !c.IsStunned() {
+		damage /= 2
+	}
+	return damage
+}
+
 // CreepStats is a set of creep statistics.
 type CreepStats struct {
 	MaxHP       int
@@ -71,12 +136,41 @@ type CreepStats struct {
 	ScoreReward int
 	CardsReward int
 	Traits      CreepTraitList
+
+	// Resistances describes how this creep reacts to the damage elements.
+	Resistances Resistances
+
+	CombatStats
 }
 
+// Resistances maps a damage element to a resistance percentage.
+// Positive values reduce the damage taken, negative values increase it.
+// For example, -100 means that the element deals x2 damage.
+type Resistances map[Element]int
+
 // Avatar is a hero status information.
 type Avatar struct {
 	HP int
 	MP int
+
+	// Class is a selected avatar class.
+	// ClassNone means that the classic avatar is used.
+	Class AvatarClass
+
+	// Level is a current avatar level.
+	// Note that level number starts with one, not zero.
+	Level int
+
+	// XP is an experience collected on the current level.
+	// Experience is only collected when leveling is enabled.
+	XP int
+
+	// Block is an amount of damage that is absorbed from the next hit.
+	Block int
+
+	// Focused avatar deals double damage with the next damage card.
+	Focused bool
+
 	AvatarStats
 }
 
@@ -84,6 +178,25 @@ type Avatar struct {
 type AvatarStats struct {
 	MaxHP int
 	MaxMP int
+
+	CombatStats
+}
+
+// CombatStats is a set of attack and defense rolls parameters.
+// They're only used when the combat rolls are enabled.
+// All values are percentages.
+type CombatStats struct {
+	// Accuracy is subtracted from the target Evasion.
+	Accuracy int
+
+	// Evasion is a chance to avoid an incoming attack.
+	Evasion int
+
+	// CritChance is a chance to deal a critical hit.
+	CritChance int
+
+	// CritBonus is an extra damage dealt by the critical hits.
+	CritBonus int
 }
 
 // Card is a hero deck card information.
@@ -117,6 +230,36 @@ type CardStats struct {
 	// IsOffensive tells whether this card targets enemy.
 	// If it's not, it either targets you or has some special effect like "Retreat".
 	IsOffensive bool
+
+	// Element is a damage type of the offensive card.
+	// See CreepStats.Resistances.
+	Element Element
+
+	// Accuracy and CritChance are added to the avatar CombatStats
+	// when this card is used to attack.
+	Accuracy   int
+	CritChance int
+}
+
+// Potion is a consumable item information.
+type Potion struct {
+	// Type is a potion type, like "PotionHealing".
+	Type PotionType
+
+	PotionStats
+}
+
+// PotionStats is a set of potion statistics.
+type PotionStats struct {
+	// Effect is a description-like string that explains the Power field meaning.
+	Effect string
+
+	// Power is a potion effectiveness.
+	Power IntRange
+
+	// FreeAction potions don't take the turn: a card is chosen right after them.
+	// Other potions are used instead of playing a card.
+	FreeAction bool
 }
 
 // IntRange is an inclusive integer range from Low() to High().
@@ -146,6 +289,70 @@ const (
 	CardStun
 	CardHeal
 	CardParry
+
+	// Class signature cards.
+	// They're unlimited, but only available for the specific avatar class.
+
+	CardShieldBash
+	CardChainLightning
+	CardBackstab
+
+	// Expansion cards.
+	// They're not given as rewards in the classic mode.
+
+	CardBlock
+	CardDrain
+	CardPoison
+	CardFocus
+	CardMeditate
+	CardSmokeBomb
+	CardScrollOfInsight
+)
+
+// PotionType is an enum-like type for potions.
+type PotionType int
+
+// All potion types.
+//go:generate stringer -type=PotionType -trimprefix=Potion
+const (
+	// PotionNone is used to skip the potion usage.
+	PotionNone PotionType = iota
+
+	PotionHealing
+	PotionMana
+	PotionStoneskin
+	PotionGreaterHealing
+)
+
+// AvatarClass is an enum-like type for avatar classes.
+type AvatarClass int
+
+// All avatar classes.
+//go:generate stringer -type=AvatarClass -trimprefix=Class
+const (
+	ClassNone AvatarClass = iota
+	ClassWarrior
+	ClassMage
+	ClassRogue
+)
+
+// Perk is an enum-like type for level-up bonuses.
+type Perk int
+
+// All perks.
+//go:generate stringer -type=Perk -trimprefix=Perk
+const (
+	// PerkVitality increases MaxHP.
+	PerkVitality Perk = iota
+
+	// PerkWisdom increases MaxMP.
+	PerkWisdom
+
+	// PerkStrength increases non-magical damage cards power.
+	PerkStrength
+
+	// PerkSorcery increases magical damage cards power.
+	PerkSorcery
 )
 
 // CreepType is an enum-like type for creeps.
@@ -161,6 +368,47 @@ const (
 	CreepFairy
 	CreepMummy
 	CreepDragon
+	CreepTroll
+	CreepGolem
+	CreepVampire
+	CreepSlime
+	CreepGhost
+)
+
+// CreepIntent is an enum-like type for creep actions.
+// Creep intent is known one move ahead, so you can react to it.
+type CreepIntent int
+
+// All creep intents.
+//go:generate stringer -type=CreepIntent -trimprefix=Intent
+const (
+	// IntentAttack is a regular attack that deals Damage.
+	IntentAttack CreepIntent = iota
+
+	// IntentCharge is a preparation for a heavy attack.
+	// It's always followed by IntentHeavyAttack, unless the creep is stunned.
+	IntentCharge
+
+	// IntentHeavyAttack is an attack that deals double damage.
+	IntentHeavyAttack
+
+	// IntentSummon adds a minion that attacks along with its master.
+	IntentSummon
+
+	// IntentHeal restores some creep HP.
+	IntentHeal
+
+	// IntentDefend halves the damage the creep receives during this turn.
+	// Defending creeps can't be stunned.
+	IntentDefend
+
+	// IntentFlee means that creep is going to run away.
+	// Fleeing creeps give no rewards.
+	IntentFlee
+
+	// IntentCast is a magical attack that deals Damage.
+	// It can't be parried.
+	IntentCast
 )
 
 // CreepTraitList is convenience wrapper over a slice of creep traits.
@@ -187,4 +435,103 @@ const (
 	TraitWeakToFire
 	TraitSlow
 	TraitRanged
+
+	// TraitRegeneration creeps recover some HP every turn.
+	TraitRegeneration
+
+	// TraitThorns creeps reflect a part of the physical damage they take.
+	TraitThorns
+
+	// TraitLifesteal creeps recover HP by dealing damage.
+	TraitLifesteal
+
+	// TraitSplit creeps split into a smaller creep when defeated for the first time.
+	TraitSplit
+
+	// TraitIncorporeal creeps can't be harmed by the physical attacks.
+	TraitIncorporeal
+
+	// TraitFast creeps are hard to escape and strike harder at the retreating avatar.
+	// Creeps get this trait only when the risky retreat is enabled.
+	TraitFast
+)
+
+// Element is an enum-like type for damage types.
+type Element int
+
+// All damage elements.
+//go:generate stringer -type=Element -trimprefix=Element
+const (
+	ElementPhysical Element = iota
+	ElementArcane
+	ElementFire
+	ElementLightning
+	ElementFrost
+	ElementHoly
+	ElementPoison
+)
+
+// DraftOffer describes the deck building options available before the run.
+// See Loadout.
+type DraftOffer struct {
+	// Budget is a number of draft points that can be spent.
+	Budget int
+
+	// CardPrices maps a card type to a draft points cost of its single copy.
+	CardPrices map[CardType]int
+
+	// DropRefunds maps unlimited card types that can be removed
+	// from the deck to a number of draft points received for that.
+	DropRefunds map[CardType]int
+
+	// HPPrice is a draft points cost of +1 MaxHP.
+	HPPrice int
+
+	// MPPrice is a draft points cost of +1 MaxMP.
+	MPPrice int
+}
+
+// Loadout is a starting deck and avatar stats selected during the draft.
+type Loadout struct {
+	// Cards maps a card type to a number of its copies to take.
+	Cards map[CardType]int
+
+	// Drop is a list of unlimited cards to remove from the deck.
+	Drop []CardType
+
+	// ExtraHP is added to the avatar MaxHP.
+	ExtraHP int
+
+	// ExtraMP is added to the avatar MaxMP.
+	ExtraMP int
+}
+
+// PathOption is a dungeon map branch description.
+// Path options are only available in the map mode, see ChoosePath.
+type PathOption struct {
+	Kind PathKind
+
+	// Tier is a creep difficulty level for PathCreep branches.
+	// Tier 1 creeps are the weakest ones, tier 3 creeps are the strongest.
+	// For other path kinds it's 0.
+	Tier int
+}
+
+// PathKind is an enum-like type for dungeon map branches.
+type PathKind int
+
+// All path kinds.
+//go:generate stringer -type=PathKind -trimprefix=Path
+const (
+	// PathCreep leads to a creep encounter of the specified tier.
+	PathCreep PathKind = iota
+
+	// PathShop leads to a shop where score points can be exchanged for a card.
+	PathShop
+
+	// PathRest leads to a rest site that recovers some HP and MP.
+	PathRest
+
+	// PathBoss leads to the Dragon lair.
+	PathBoss
 )
@@ -1,5 +1,5 @@
 module github.com/quasilyte/gophers-and-dragons
 
-go 1.15
+go 1.18
 
 require github.com/traefik/yaegi v0.9.1
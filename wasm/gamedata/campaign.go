@@ -0,0 +1,64 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// Chapter is a campaign chapter with its own ruleset and creeps.
+type Chapter struct {
+	Name   string
+	Rounds int
+
+	// Bosses and Intents have the same meaning as the sim.Config fields.
+	Bosses  bool
+	Intents bool
+
+	// Creeps is a chapter creep pool for the non-boss rounds.
+	// Unlocked creeps are added to this pool.
+	Creeps []game.CreepType
+
+	// Boss is a creep that is encountered at the last round.
+	Boss game.CreepType
+
+	// UnlockCards and UnlockCreeps are unlocked when the chapter is cleared.
+	UnlockCards  []game.CardType
+	UnlockCreeps []game.CreepType
+}
+
+// Chapters is the campaign chapters list in the order they are played.
+var Chapters = []Chapter{
+	{
+		Name:         "Gopher hills",
+		Rounds:       5,
+		Creeps:       []game.CreepType{game.CreepCheepy, game.CreepImp},
+		Boss:         game.CreepLion,
+		UnlockCards:  []game.CardType{game.CardFirebolt},
+		UnlockCreeps: []game.CreepType{game.CreepLion, game.CreepFairy},
+	},
+
+	{
+		Name:         "Fairy woods",
+		Rounds:       8,
+		Intents:      true,
+		Creeps:       []game.CreepType{game.CreepImp},
+		Boss:         game.CreepMummy,
+		UnlockCards:  []game.CardType{game.CardHeal},
+		UnlockCreeps: []game.CreepType{game.CreepMummy},
+	},
+
+	{
+		Name:    "Dragon lair",
+		Rounds:  10,
+		Bosses:  true,
+		Intents: true,
+		Creeps:  []game.CreepType{game.CreepLion, game.CreepFairy},
+		Boss:    game.CreepDragon,
+	},
+}
+
+// CampaignCards are reward cards that are available from the start.
+var CampaignCards = []game.CardType{
+	game.CardPowerAttack,
+	game.CardStun,
+	game.CardParry,
+}
@@ -11,6 +11,7 @@ var Cards = map[game.CardType]game.CardStats{
 		IsOffensive: true,
 		Power:       game.IntRange{2, 4},
 		Effect:      "damage",
+		Element:     game.ElementPhysical,
 	},
 
 	game.CardPowerAttack: {
@@ -19,6 +20,8 @@ var Cards = map[game.CardType]game.CardStats{
 		IsOffensive: true,
 		Power:       game.IntRange{4, 5},
 		Effect:      "damage",
+		Element:     game.ElementPhysical,
+		CritChance:  10,
 	},
 
 	game.CardStun: {
@@ -35,6 +38,8 @@ var Cards = map[game.CardType]game.CardStats{
 		IsOffensive: true,
 		Power:       game.IntRange{3, 3},
 		Effect:      "magical damage",
+		Element:     game.ElementArcane,
+		Accuracy:    100,
 	},
 
 	game.CardFirebolt: {
@@ -43,6 +48,8 @@ var Cards = map[game.CardType]game.CardStats{
 		IsOffensive: true,
 		Power:       game.IntRange{4, 6},
 		Effect:      "magical damage",
+		Element:     game.ElementFire,
+		Accuracy:    100,
 	},
 
 	game.CardRetreat: {
@@ -72,8 +79,99 @@ var Cards = map[game.CardType]game.CardStats{
 		IsMagic:     false,
 		IsOffensive: false,
 	},
+
+	game.CardShieldBash: {
+		MP:          2,
+		IsMagic:     false,
+		IsOffensive: true,
+		Power:       game.IntRange{1, 2},
+		Effect:      "damage",
+		Element:     game.ElementPhysical,
+	},
+
+	game.CardChainLightning: {
+		MP:          4,
+		IsMagic:     true,
+		IsOffensive: true,
+		Power:       game.IntRange{6, 8},
+		Effect:      "magical damage",
+		Element:     game.ElementLightning,
+		Accuracy:    100,
+	},
+
+	game.CardBackstab: {
+		MP:          1,
+		IsMagic:     false,
+		IsOffensive: true,
+		Power:       game.IntRange{3, 5},
+		Effect:      "damage",
+		Element:     game.ElementPhysical,
+		Accuracy:    20,
+		CritChance:  30,
+	},
+
+	game.CardBlock: {
+		MP:          1,
+		IsMagic:     false,
+		IsOffensive: false,
+		Power:       game.IntRange{3, 5},
+		Effect:      "damage blocked",
+	},
+
+	game.CardDrain: {
+		MP:          3,
+		IsMagic:     true,
+		IsOffensive: true,
+		Power:       game.IntRange{3, 4},
+		Effect:      "magical damage",
+		Element:     game.ElementArcane,
+		Accuracy:    100,
+	},
+
+	game.CardPoison: {
+		MP:          1,
+		IsMagic:     false,
+		IsOffensive: true,
+		Power:       game.IntRange{3, 3},
+		Effect:      "turns poisoned",
+		Element:     game.ElementPoison,
+	},
+
+	game.CardFocus: {
+		MP:          1,
+		IsMagic:     false,
+		IsOffensive: false,
+	},
+
+	game.CardMeditate: {
+		MP:          0,
+		IsMagic:     false,
+		IsOffensive: false,
+		Power:       game.IntRange{3, 4},
+		Effect:      "MP recovered",
+	},
+
+	game.CardSmokeBomb: {
+		MP:          0,
+		IsMagic:     false,
+		IsOffensive: false,
+	},
+
+	game.CardScrollOfInsight: {
+		MP:          0,
+		IsMagic:     true,
+		IsOffensive: false,
+		Power:       game.IntRange{2, 2},
+		Effect:      "creeps revealed",
+	},
 }
 
 func GetCardStats(typ game.CardType) game.CardStats {
 	return Cards[typ]
 }
+
+// IsExpansionCard reports whether the card is only given as a reward
+// when the expansion cards are enabled.
+func IsExpansionCard(typ game.CardType) bool {
+	return typ >= game.CardBlock
+}
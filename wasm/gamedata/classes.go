@@ -0,0 +1,70 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// ClassStats is a set of avatar class properties.
+type ClassStats struct {
+	AvatarStats game.AvatarStats
+
+	// Cards maps a card type to its starting count.
+	Cards map[game.CardType]int
+
+	// SignatureCard is an unlimited card that is only available for this class.
+	SignatureCard game.CardType
+}
+
+var classes = map[game.AvatarClass]ClassStats{
+	game.ClassWarrior: {
+		AvatarStats: game.AvatarStats{
+			MaxHP:       50,
+			MaxMP:       10,
+			CombatStats: game.CombatStats{Evasion: 5, CritChance: 10, CritBonus: 50},
+		},
+		Cards: map[game.CardType]int{
+			game.CardPowerAttack: 2,
+		},
+		SignatureCard: game.CardShieldBash,
+	},
+
+	game.ClassMage: {
+		AvatarStats: game.AvatarStats{
+			MaxHP:       30,
+			MaxMP:       30,
+			CombatStats: game.CombatStats{Evasion: 10, CritChance: 5, CritBonus: 50},
+		},
+		Cards: map[game.CardType]int{
+			game.CardHeal: 1,
+		},
+		SignatureCard: game.CardChainLightning,
+	},
+
+	game.ClassRogue: {
+		AvatarStats: game.AvatarStats{
+			MaxHP:       40,
+			MaxMP:       15,
+			CombatStats: game.CombatStats{Evasion: 20, CritChance: 20, CritBonus: 100},
+		},
+		Cards: map[game.CardType]int{
+			game.CardStun:  1,
+			game.CardParry: 1,
+		},
+		SignatureCard: game.CardBackstab,
+	},
+}
+
+func GetClassStats(class game.AvatarClass) (ClassStats, bool) {
+	stats, ok := classes[class]
+	return stats, ok
+}
+
+// IsSignatureCard reports whether card is a class signature card.
+func IsSignatureCard(typ game.CardType) bool {
+	for _, stats := range classes {
+		if stats.SignatureCard == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,13 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// AvatarCombatStats is a classic avatar combat rolls parameters.
+// Classes define their own combat stats.
+var AvatarCombatStats = game.CombatStats{
+	Evasion:    10,
+	CritChance: 10,
+	CritBonus:  50,
+}
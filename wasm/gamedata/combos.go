@@ -0,0 +1,56 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// Combo is a card sequence that triggers a bonus when its last card is played.
+type Combo struct {
+	Name string
+
+	// Cards is a sequence of cards played during the consecutive turns.
+	Cards []game.CardType
+
+	// DamagePercent is an extra damage dealt by the last card.
+	DamagePercent int
+
+	// Effects are applied after the last card effects.
+	Effects []CardEffect
+
+	// If is a combo precondition that is checked before the last card is played.
+	If Condition
+}
+
+// MaxComboLength is the longest combo cards sequence length.
+const MaxComboLength = 2
+
+// Combos is a list of card combos.
+// If several combos match, the first one is triggered.
+var Combos = []Combo{
+	{
+		Name:          "Riposte",
+		Cards:         []game.CardType{game.CardParry, game.CardAttack},
+		DamagePercent: 100,
+	},
+
+	{
+		Name:          "Execute",
+		Cards:         []game.CardType{game.CardStun, game.CardPowerAttack},
+		DamagePercent: 100,
+		If:            Condition{Kind: CondStunned},
+	},
+
+	{
+		Name:          "Kindling",
+		Cards:         []game.CardType{game.CardMagicArrow, game.CardFirebolt},
+		DamagePercent: 50,
+	},
+
+	{
+		Name:  "Shield slam",
+		Cards: []game.CardType{game.CardBlock, game.CardAttack},
+		Effects: []CardEffect{
+			{Kind: EffectStatus, Status: StatusStun, Amount: 1, If: Condition{Kind: CondNotStunned}},
+		},
+	},
+}
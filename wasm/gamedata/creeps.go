@@ -13,6 +13,7 @@ var creeps = map[game.CreepType]game.CreepStats{
 		Traits: []game.CreepTrait{
 			game.TraitCoward,
 		},
+		CombatStats: game.CombatStats{Evasion: 20},
 	},
 
 	game.CreepImp: {
@@ -20,6 +21,7 @@ var creeps = map[game.CreepType]game.CreepStats{
 		Damage:      game.IntRange{3, 4},
 		ScoreReward: 5,
 		CardsReward: 1,
+		CombatStats: game.CombatStats{Evasion: 10, CritChance: 10, CritBonus: 50},
 	},
 
 	game.CreepLion: {
@@ -27,6 +29,7 @@ var creeps = map[game.CreepType]game.CreepStats{
 		Damage:      game.IntRange{2, 3},
 		ScoreReward: 6,
 		CardsReward: 2,
+		CombatStats: game.CombatStats{CritChance: 15, CritBonus: 50},
 	},
 
 	game.CreepFairy: {
@@ -37,6 +40,7 @@ var creeps = map[game.CreepType]game.CreepStats{
 		Traits: []game.CreepTrait{
 			game.TraitRanged,
 		},
+		CombatStats: game.CombatStats{Accuracy: 10, Evasion: 25},
 	},
 
 	game.CreepMummy: {
@@ -48,6 +52,83 @@ var creeps = map[game.CreepType]game.CreepStats{
 			game.TraitWeakToFire,
 			game.TraitSlow,
 		},
+		Resistances: game.Resistances{
+			game.ElementFire: -100,
+		},
+	},
+
+	game.CreepTroll: {
+		MaxHP:       16,
+		Damage:      game.IntRange{3, 5},
+		ScoreReward: 14,
+		CardsReward: 2,
+		Traits: []game.CreepTrait{
+			game.TraitRegeneration,
+		},
+		Resistances: game.Resistances{
+			game.ElementFire: -50,
+		},
+		CombatStats: game.CombatStats{CritChance: 10, CritBonus: 50},
+	},
+
+	game.CreepGolem: {
+		MaxHP:       22,
+		Damage:      game.IntRange{2, 4},
+		ScoreReward: 16,
+		CardsReward: 3,
+		Traits: []game.CreepTrait{
+			game.TraitThorns,
+			game.TraitSlow,
+		},
+		Resistances: game.Resistances{
+			game.ElementPhysical:  25,
+			game.ElementLightning: -50,
+		},
+	},
+
+	game.CreepVampire: {
+		MaxHP:       14,
+		Damage:      game.IntRange{3, 4},
+		ScoreReward: 13,
+		CardsReward: 2,
+		Traits: []game.CreepTrait{
+			game.TraitLifesteal,
+		},
+		Resistances: game.Resistances{
+			game.ElementHoly: -100,
+		},
+		CombatStats: game.CombatStats{Evasion: 15, CritChance: 10, CritBonus: 50},
+	},
+
+	game.CreepSlime: {
+		MaxHP:       8,
+		Damage:      game.IntRange{1, 3},
+		ScoreReward: 8,
+		CardsReward: 1,
+		Traits: []game.CreepTrait{
+			game.TraitSplit,
+			game.TraitSlow,
+		},
+		Resistances: game.Resistances{
+			game.ElementPoison: 100,
+			game.ElementFrost:  -50,
+		},
+	},
+
+	game.CreepGhost: {
+		MaxHP:       8,
+		Damage:      game.IntRange{3, 4},
+		ScoreReward: 12,
+		CardsReward: 2,
+		Traits: []game.CreepTrait{
+			game.TraitIncorporeal,
+		},
+		Resistances: game.Resistances{
+			game.ElementPhysical: 100,
+			game.ElementPoison:   100,
+			game.ElementHoly:     -100,
+		},
+		CombatStats: game.CombatStats{Evasion: 30},
 	},
 
 	game.CreepDragon: {
@@ -58,9 +139,143 @@ var creeps = map[game.CreepType]game.CreepStats{
 		Traits: []game.CreepTrait{
 			game.TraitMagicImmunity,
 		},
+		CombatStats: game.CombatStats{Accuracy: 20, CritChance: 10, CritBonus: 50},
 	},
 }
 
 func GetCreepStats(typ game.CreepType) game.CreepStats {
 	return creeps[typ]
 }
+
+// riskyRetreatTraits are added to the creep traits only with the risky retreat,
+// so the classic creeps stay the same.
+var riskyRetreatTraits = map[game.CreepType][]game.CreepTrait{
+	game.CreepLion:    {game.TraitFast},
+	game.CreepVampire: {game.TraitFast},
+}
+
+func GetRiskyRetreatTraits(typ game.CreepType) []game.CreepTrait {
+	return riskyRetreatTraits[typ]
+}
+
+const (
+	// RegenerationHP is an amount of HP recovered by TraitRegeneration creeps every turn.
+	RegenerationHP = 2
+
+	// ThornsPercent is a part of the physical damage reflected by TraitThorns creeps.
+	ThornsPercent = 50
+
+	// LifestealPercent is a part of the damage dealt that TraitLifesteal creeps recover as HP.
+	LifestealPercent = 50
+
+	// RetreatChance is a risky retreat success percentage.
+	// Retreating from TraitSlow creeps always succeeds.
+	RetreatChance = 75
+
+	// FastRetreatChance is a risky retreat success percentage for TraitFast creeps.
+	FastRetreatChance = 50
+
+	// RetreatPenaltyPercent is a part of the creep score reward that is lost after the risky retreat.
+	RetreatPenaltyPercent = 50
+)
+
+// BossStats is a set of scripted boss abilities parameters.
+type BossStats struct {
+	// HealPower is an amount of HP restored by the heal ability.
+	HealPower game.IntRange
+
+	// MaxMinions is a limit of minions that can be summoned.
+	MaxMinions int
+
+	// MinionDamage is a damage dealt by every minion during the boss move.
+	MinionDamage game.IntRange
+}
+
+var bosses = map[game.CreepType]BossStats{
+	game.CreepDragon: {
+		HealPower:    game.IntRange{6, 8},
+		MaxMinions:   2,
+		MinionDamage: game.IntRange{1, 2},
+	},
+}
+
+func GetBossStats(typ game.CreepType) (BossStats, bool) {
+	stats, ok := bosses[typ]
+	return stats, ok
+}
+
+// IntentWeight is a relative probability of the creep intent.
+type IntentWeight struct {
+	Intent game.CreepIntent
+	Weight int
+}
+
+var creepIntents = map[game.CreepType][]IntentWeight{
+	game.CreepCheepy: {
+		{game.IntentAttack, 60},
+		{game.IntentDefend, 10},
+		{game.IntentFlee, 30},
+	},
+
+	game.CreepImp: {
+		{game.IntentAttack, 60},
+		{game.IntentHeavyAttack, 20},
+		{game.IntentCast, 20},
+	},
+
+	game.CreepLion: {
+		{game.IntentAttack, 60},
+		{game.IntentHeavyAttack, 25},
+		{game.IntentDefend, 15},
+	},
+
+	game.CreepFairy: {
+		{game.IntentAttack, 50},
+		{game.IntentCast, 40},
+		{game.IntentFlee, 10},
+	},
+
+	game.CreepMummy: {
+		{game.IntentAttack, 60},
+		{game.IntentHeavyAttack, 30},
+		{game.IntentDefend, 10},
+	},
+
+	game.CreepTroll: {
+		{game.IntentAttack, 60},
+		{game.IntentHeavyAttack, 30},
+		{game.IntentDefend, 10},
+	},
+
+	game.CreepGolem: {
+		{game.IntentAttack, 50},
+		{game.IntentHeavyAttack, 20},
+		{game.IntentDefend, 30},
+	},
+
+	game.CreepVampire: {
+		{game.IntentAttack, 60},
+		{game.IntentCast, 30},
+		{game.IntentFlee, 10},
+	},
+
+	game.CreepSlime: {
+		{game.IntentAttack, 80},
+		{game.IntentDefend, 20},
+	},
+
+	game.CreepGhost: {
+		{game.IntentAttack, 50},
+		{game.IntentCast, 40},
+		{game.IntentFlee, 10},
+	},
+
+	game.CreepDragon: {
+		{game.IntentAttack, 70},
+		{game.IntentHeavyAttack, 30},
+	},
+}
+
+func GetCreepIntents(typ game.CreepType) []IntentWeight {
+	return creepIntents[typ]
+}
@@ -0,0 +1,44 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// GetDraftOffer returns the deck building options.
+// Only the cards for which allowed returns true can be bought;
+// a nil allowed func permits all cards.
+// Every call returns a new offer object, so it can be modified freely.
+func GetDraftOffer(allowed func(game.CardType) bool) game.DraftOffer {
+	offer := game.DraftOffer{
+		Budget: 10,
+		CardPrices: map[game.CardType]int{
+			game.CardPowerAttack: 2,
+			game.CardStun:        3,
+			game.CardParry:       3,
+			game.CardFirebolt:    4,
+			game.CardHeal:        5,
+
+			game.CardBlock:           2,
+			game.CardPoison:          2,
+			game.CardFocus:           2,
+			game.CardMeditate:        2,
+			game.CardSmokeBomb:       2,
+			game.CardScrollOfInsight: 1,
+			game.CardDrain:           4,
+		},
+		DropRefunds: map[game.CardType]int{
+			game.CardMagicArrow: 6,
+			game.CardRest:       5,
+		},
+		HPPrice: 1,
+		MPPrice: 2,
+	}
+	if allowed != nil {
+		for typ := range offer.CardPrices {
+			if !allowed(typ) {
+				delete(offer.CardPrices, typ)
+			}
+		}
+	}
+	return offer
+}
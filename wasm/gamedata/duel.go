@@ -0,0 +1,15 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// DuelCards maps a card type to its starting count in the duel mode.
+// Both duelists receive the same cards.
+var DuelCards = map[game.CardType]int{
+	game.CardPowerAttack: 2,
+	game.CardFirebolt:    1,
+	game.CardStun:        1,
+	game.CardHeal:        1,
+	game.CardParry:       2,
+}
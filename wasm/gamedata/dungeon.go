@@ -0,0 +1,21 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// CreepTiers maps a map mode creep tier to the creeps that can be encountered.
+var CreepTiers = map[int][]game.CreepType{
+	1: {game.CreepCheepy, game.CreepImp},
+	2: {game.CreepLion, game.CreepFairy, game.CreepSlime, game.CreepGhost},
+	3: {game.CreepMummy, game.CreepTroll, game.CreepGolem, game.CreepVampire},
+}
+
+// ShopCardPrice is a score points cost of a card bought in a shop.
+const ShopCardPrice = 5
+
+// RestSiteHP is an amount of HP recovered at the rest site.
+var RestSiteHP = game.IntRange{8, 12}
+
+// RestSiteMP is an amount of MP recovered at the rest site.
+var RestSiteMP = game.IntRange{3, 5}
@@ -0,0 +1,208 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// EffectKind is a card effect kind.
+type EffectKind int
+
+const (
+	// EffectDamage deals the card element damage to the creep.
+	EffectDamage EffectKind = iota
+
+	// EffectHeal recovers the avatar HP.
+	EffectHeal
+
+	// EffectStatus applies the effect status.
+	// Depending on the status, it targets either the creep or the avatar.
+	EffectStatus
+
+	// EffectGainMP recovers the avatar MP.
+	EffectGainMP
+
+	// EffectDrawCard adds random cards to the avatar deck.
+	EffectDrawCard
+
+	// EffectDestroyMinion destroys one of the creep minions.
+	EffectDestroyMinion
+
+	// EffectParry reflects the creep melee attack during this turn.
+	EffectParry
+
+	// EffectRetreat makes the avatar leave the round after the creep move.
+	EffectRetreat
+
+	// EffectDrain recovers the avatar HP by the damage dealt by the card.
+	EffectDrain
+
+	// EffectEvade makes the creep skip its move during this turn.
+	EffectEvade
+
+	// EffectReveal fills the game.State Forecast with the upcoming creeps.
+	EffectReveal
+)
+
+// Status is a creep or avatar status that can be applied by the card effects.
+type Status int
+
+const (
+	// StatusStun makes the creep skip its moves.
+	StatusStun Status = iota
+
+	// StatusPoison makes the creep take PoisonDamage at the end of every turn.
+	StatusPoison
+
+	// StatusBlock makes the avatar absorb some damage from the next hit.
+	StatusBlock
+
+	// StatusFocus makes the avatar deal double damage with the next damage card.
+	StatusFocus
+)
+
+// PoisonDamage is a damage dealt to the poisoned creep every turn.
+const PoisonDamage = 2
+
+// ConditionKind is a card effect precondition kind.
+type ConditionKind int
+
+const (
+	// CondAlways is a condition that is always true.
+	CondAlways ConditionKind = iota
+
+	// CondTrait requires the creep to have the condition trait.
+	CondTrait
+
+	// CondNoTrait requires the creep to not have the condition trait.
+	CondNoTrait
+
+	// CondNotStunned requires the creep to be not stunned.
+	CondNotStunned
+
+	// CondStunned requires the creep to be stunned.
+	CondStunned
+)
+
+// Condition is a card effect precondition.
+type Condition struct {
+	Kind  ConditionKind
+	Trait game.CreepTrait
+}
+
+// CardEffect is a single step of the card effect.
+// Card effects are applied in the order they are listed.
+type CardEffect struct {
+	Kind EffectKind
+
+	// Status is a status applied by EffectStatus.
+	Status Status
+
+	// Amount is a fixed effect value.
+	// If it's zero, the card Power is rolled instead.
+	Amount int
+
+	// Interrupt makes the status interrupt the creep intent.
+	// Interrupting effects are blocked by the defending creeps.
+	Interrupt bool
+
+	// If is an effect precondition.
+	// Effects with unsatisfied conditions are skipped.
+	If Condition
+}
+
+var cardEffects = map[game.CardType][]CardEffect{
+	game.CardAttack: {
+		{Kind: EffectDamage},
+	},
+
+	game.CardPowerAttack: {
+		{Kind: EffectDamage},
+	},
+
+	game.CardStun: {
+		{Kind: EffectStatus, Status: StatusStun, Interrupt: true},
+	},
+
+	game.CardMagicArrow: {
+		{Kind: EffectDamage},
+	},
+
+	game.CardFirebolt: {
+		{Kind: EffectDamage},
+	},
+
+	game.CardRetreat: {
+		{Kind: EffectRetreat},
+	},
+
+	game.CardRest: {
+		{Kind: EffectHeal},
+	},
+
+	game.CardHeal: {
+		{Kind: EffectHeal},
+	},
+
+	game.CardParry: {
+		{Kind: EffectParry},
+	},
+
+	game.CardShieldBash: {
+		{Kind: EffectDamage},
+		{Kind: EffectStatus, Status: StatusStun, Amount: 1, If: Condition{Kind: CondNotStunned}},
+	},
+
+	game.CardChainLightning: {
+		{Kind: EffectDamage},
+		{Kind: EffectDestroyMinion},
+	},
+
+	game.CardBackstab: {
+		{Kind: EffectDamage},
+	},
+
+	game.CardBlock: {
+		{Kind: EffectStatus, Status: StatusBlock},
+	},
+
+	game.CardDrain: {
+		{Kind: EffectDamage},
+		{Kind: EffectDrain},
+	},
+
+	game.CardPoison: {
+		{Kind: EffectStatus, Status: StatusPoison},
+	},
+
+	game.CardFocus: {
+		{Kind: EffectStatus, Status: StatusFocus, Amount: 1},
+	},
+
+	game.CardMeditate: {
+		{Kind: EffectGainMP},
+	},
+
+	game.CardSmokeBomb: {
+		{Kind: EffectEvade},
+		{Kind: EffectRetreat},
+	},
+
+	game.CardScrollOfInsight: {
+		{Kind: EffectReveal},
+	},
+}
+
+// GetCardEffects returns the effects applied when the card is played.
+func GetCardEffects(typ game.CardType) []CardEffect {
+	return cardEffects[typ]
+}
+
+// HasEffect reports whether the card has an effect of the specified kind.
+func HasEffect(typ game.CardType, kind EffectKind) bool {
+	for _, e := range cardEffects[typ] {
+		if e.Kind == kind {
+			return true
+		}
+	}
+	return false
+}
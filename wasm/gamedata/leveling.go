@@ -0,0 +1,33 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// Perks is a list of all level-up bonuses.
+var Perks = []game.Perk{
+	game.PerkVitality,
+	game.PerkWisdom,
+	game.PerkStrength,
+	game.PerkSorcery,
+}
+
+// PerkChoices is a number of perks offered on level-up.
+const PerkChoices = 2
+
+const (
+	// VitalityHP is a MaxHP bonus of PerkVitality.
+	VitalityHP = 5
+
+	// WisdomMP is a MaxMP bonus of PerkWisdom.
+	WisdomMP = 3
+
+	// PerkPower is a card power bonus of PerkStrength and PerkSorcery.
+	PerkPower = 1
+)
+
+// XPToLevelUp returns an amount of experience that is needed
+// to get the next level from the specified one.
+func XPToLevelUp(level int) int {
+	return 10 * level
+}
@@ -0,0 +1,25 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// DungeonMasterBudget is an amount of points the dungeon master
+// can spend on creeps during a single run.
+const DungeonMasterBudget = 20
+
+// CreepCosts maps a creep to its dungeon master price.
+// Creeps that are not listed can't be selected by the dungeon master.
+var CreepCosts = map[game.CreepType]int{
+	game.CreepCheepy: 0,
+	game.CreepImp:    1,
+	game.CreepLion:   2,
+	game.CreepFairy:  4,
+	game.CreepMummy:  5,
+
+	game.CreepSlime:   3,
+	game.CreepGhost:   4,
+	game.CreepTroll:   5,
+	game.CreepVampire: 5,
+	game.CreepGolem:   6,
+}
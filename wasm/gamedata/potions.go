@@ -0,0 +1,69 @@
+package gamedata
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// MaxPotions is a potion inventory capacity.
+// Potions that don't fit into the inventory are left behind.
+const MaxPotions = 3
+
+var Potions = map[game.PotionType]game.PotionStats{
+	game.PotionHealing: {
+		Power:      game.IntRange{5, 7},
+		Effect:     "heal",
+		FreeAction: true,
+	},
+
+	game.PotionMana: {
+		Power:      game.IntRange{2, 3},
+		Effect:     "mana restore",
+		FreeAction: true,
+	},
+
+	game.PotionStoneskin: {
+		Power:      game.IntRange{4, 6},
+		Effect:     "damage blocked",
+		FreeAction: true,
+	},
+
+	game.PotionGreaterHealing: {
+		Power:  game.IntRange{15, 20},
+		Effect: "heal",
+	},
+}
+
+func GetPotionStats(typ game.PotionType) game.PotionStats {
+	return Potions[typ]
+}
+
+// PotionDrop is a chance to get a potion from a defeated creep.
+type PotionDrop struct {
+	Potion game.PotionType
+
+	// Chance is a drop probability percentage.
+	Chance int
+}
+
+// PotionDrops maps a creep to its potion drop table.
+// Every drop is rolled separately, so one creep can drop several potions.
+// Creeps that are not listed drop nothing.
+var PotionDrops = map[game.CreepType][]PotionDrop{
+	game.CreepCheepy: {{Potion: game.PotionHealing, Chance: 20}},
+	game.CreepImp:    {{Potion: game.PotionMana, Chance: 25}},
+	game.CreepLion:   {{Potion: game.PotionHealing, Chance: 30}},
+	game.CreepFairy: {
+		{Potion: game.PotionMana, Chance: 40},
+		{Potion: game.PotionHealing, Chance: 20},
+	},
+	game.CreepMummy: {
+		{Potion: game.PotionStoneskin, Chance: 30},
+		{Potion: game.PotionGreaterHealing, Chance: 30},
+	},
+
+	game.CreepSlime:   {{Potion: game.PotionHealing, Chance: 20}},
+	game.CreepGhost:   {{Potion: game.PotionMana, Chance: 40}},
+	game.CreepTroll:   {{Potion: game.PotionHealing, Chance: 40}},
+	game.CreepGolem:   {{Potion: game.PotionStoneskin, Chance: 50}},
+	game.CreepVampire: {{Potion: game.PotionGreaterHealing, Chance: 30}},
+}
@@ -0,0 +1,258 @@
+// Package program loads user-provided Go programs with the yaegi interpreter.
+package program
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/sim"
+	"github.com/traefik/yaegi/interp"
+)
+
+// evalProgram evaluates the user program code.
+// It returns the interpreter along with the program package name.
+func evalProgram(code string) (*interp.Interpreter, string, error) {
+	i := interp.New(interp.Options{})
+
+	i.Use(map[string]map[string]reflect.Value{
+		"github.com/quasilyte/gophers-and-dragons/game": {
+			"State":          reflect.ValueOf((*game.State)(nil)),
+			"Avatar":         reflect.ValueOf((*game.Avatar)(nil)),
+			"AvatarClass":    reflect.ValueOf((*game.AvatarClass)(nil)),
+			"AvatarStats":    reflect.ValueOf((*game.AvatarStats)(nil)),
+			"Card":           reflect.ValueOf((*game.Card)(nil)),
+			"CardStats":      reflect.ValueOf((*game.CardStats)(nil)),
+			"CardType":       reflect.ValueOf((*game.CardType)(nil)),
+			"Creep":          reflect.ValueOf((*game.Creep)(nil)),
+			"CreepStats":     reflect.ValueOf((*game.CreepStats)(nil)),
+			"CreepType":      reflect.ValueOf((*game.CreepType)(nil)),
+			"CreepTrait":     reflect.ValueOf((*game.CreepTrait)(nil)),
+			"CreepIntent":    reflect.ValueOf((*game.CreepIntent)(nil)),
+			"CreepTraitList": reflect.ValueOf((*game.CreepTraitList)(nil)),
+			"IntRange":       reflect.ValueOf((*game.IntRange)(nil)),
+			"Perk":           reflect.ValueOf((*game.Perk)(nil)),
+			"PathOption":     reflect.ValueOf((*game.PathOption)(nil)),
+			"DraftOffer":     reflect.ValueOf((*game.DraftOffer)(nil)),
+			"Loadout":        reflect.ValueOf((*game.Loadout)(nil)),
+			"PathKind":       reflect.ValueOf((*game.PathKind)(nil)),
+			"Element":        reflect.ValueOf((*game.Element)(nil)),
+			"Resistances":    reflect.ValueOf((*game.Resistances)(nil)),
+			"CombatStats":    reflect.ValueOf((*game.CombatStats)(nil)),
+			"Potion":         reflect.ValueOf((*game.Potion)(nil)),
+			"PotionStats":    reflect.ValueOf((*game.PotionStats)(nil)),
+			"PotionType":     reflect.ValueOf((*game.PotionType)(nil)),
+
+			"CreepCheepy": reflect.ValueOf(game.CreepCheepy),
+			"CreepImp":    reflect.ValueOf(game.CreepImp),
+			"CreepLion":   reflect.ValueOf(game.CreepLion),
+			"CreepFairy":  reflect.ValueOf(game.CreepFairy),
+			"CreepMummy":  reflect.ValueOf(game.CreepMummy),
+			"CreepDragon": reflect.ValueOf(game.CreepDragon),
+
+			"CreepTroll":   reflect.ValueOf(game.CreepTroll),
+			"CreepGolem":   reflect.ValueOf(game.CreepGolem),
+			"CreepVampire": reflect.ValueOf(game.CreepVampire),
+			"CreepSlime":   reflect.ValueOf(game.CreepSlime),
+			"CreepGhost":   reflect.ValueOf(game.CreepGhost),
+
+			"TraitCoward":        reflect.ValueOf(game.TraitCoward),
+			"TraitMagicImmunity": reflect.ValueOf(game.TraitMagicImmunity),
+			"TraitWeakToFire":    reflect.ValueOf(game.TraitWeakToFire),
+			"TraitSlow":          reflect.ValueOf(game.TraitSlow),
+			"TraitRanged":        reflect.ValueOf(game.TraitRanged),
+			"TraitRegeneration":  reflect.ValueOf(game.TraitRegeneration),
+			"TraitThorns":        reflect.ValueOf(game.TraitThorns),
+			"TraitLifesteal":     reflect.ValueOf(game.TraitLifesteal),
+			"TraitSplit":         reflect.ValueOf(game.TraitSplit),
+			"TraitIncorporeal":   reflect.ValueOf(game.TraitIncorporeal),
+			"TraitFast":          reflect.ValueOf(game.TraitFast),
+
+			"IntentAttack":      reflect.ValueOf(game.IntentAttack),
+			"IntentCharge":      reflect.ValueOf(game.IntentCharge),
+			"IntentHeavyAttack": reflect.ValueOf(game.IntentHeavyAttack),
+			"IntentSummon":      reflect.ValueOf(game.IntentSummon),
+			"IntentHeal":        reflect.ValueOf(game.IntentHeal),
+			"IntentDefend":      reflect.ValueOf(game.IntentDefend),
+			"IntentFlee":        reflect.ValueOf(game.IntentFlee),
+			"IntentCast":        reflect.ValueOf(game.IntentCast),
+
+			"CardMagicArrow":  reflect.ValueOf(game.CardMagicArrow),
+			"CardAttack":      reflect.ValueOf(game.CardAttack),
+			"CardPowerAttack": reflect.ValueOf(game.CardPowerAttack),
+			"CardStun":        reflect.ValueOf(game.CardStun),
+			"CardFirebolt":    reflect.ValueOf(game.CardFirebolt),
+			"CardRetreat":     reflect.ValueOf(game.CardRetreat),
+			"CardRest":        reflect.ValueOf(game.CardRest),
+			"CardHeal":        reflect.ValueOf(game.CardHeal),
+			"CardParry":       reflect.ValueOf(game.CardParry),
+
+			"CardShieldBash":     reflect.ValueOf(game.CardShieldBash),
+			"CardChainLightning": reflect.ValueOf(game.CardChainLightning),
+			"CardBackstab":       reflect.ValueOf(game.CardBackstab),
+
+			"CardBlock":           reflect.ValueOf(game.CardBlock),
+			"CardDrain":           reflect.ValueOf(game.CardDrain),
+			"CardPoison":          reflect.ValueOf(game.CardPoison),
+			"CardFocus":           reflect.ValueOf(game.CardFocus),
+			"CardMeditate":        reflect.ValueOf(game.CardMeditate),
+			"CardSmokeBomb":       reflect.ValueOf(game.CardSmokeBomb),
+			"CardScrollOfInsight": reflect.ValueOf(game.CardScrollOfInsight),
+
+			"ClassNone":    reflect.ValueOf(game.ClassNone),
+			"ClassWarrior": reflect.ValueOf(game.ClassWarrior),
+			"ClassMage":    reflect.ValueOf(game.ClassMage),
+			"ClassRogue":   reflect.ValueOf(game.ClassRogue),
+
+			"PerkVitality": reflect.ValueOf(game.PerkVitality),
+			"PerkWisdom":   reflect.ValueOf(game.PerkWisdom),
+			"PerkStrength": reflect.ValueOf(game.PerkStrength),
+			"PerkSorcery":  reflect.ValueOf(game.PerkSorcery),
+
+			"ElementPhysical":  reflect.ValueOf(game.ElementPhysical),
+			"ElementArcane":    reflect.ValueOf(game.ElementArcane),
+			"ElementFire":      reflect.ValueOf(game.ElementFire),
+			"ElementLightning": reflect.ValueOf(game.ElementLightning),
+			"ElementFrost":     reflect.ValueOf(game.ElementFrost),
+			"ElementHoly":      reflect.ValueOf(game.ElementHoly),
+			"ElementPoison":    reflect.ValueOf(game.ElementPoison),
+
+			"PotionNone":           reflect.ValueOf(game.PotionNone),
+			"PotionHealing":        reflect.ValueOf(game.PotionHealing),
+			"PotionMana":           reflect.ValueOf(game.PotionMana),
+			"PotionStoneskin":      reflect.ValueOf(game.PotionStoneskin),
+			"PotionGreaterHealing": reflect.ValueOf(game.PotionGreaterHealing),
+
+			"PathCreep": reflect.ValueOf(game.PathCreep),
+			"PathShop":  reflect.ValueOf(game.PathShop),
+			"PathRest":  reflect.ValueOf(game.PathRest),
+			"PathBoss":  reflect.ValueOf(game.PathBoss),
+		},
+	})
+	// i.Use(stdlib.Symbols)
+
+	if _, err := i.Eval(code); err != nil {
+		return nil, "", err
+	}
+
+	return i, inferPackage(code), nil
+}
+
+// LoadTactic evaluates the tactic program and collects its entry points.
+func LoadTactic(code string) (*sim.Tactic, error) {
+	i, pkg, err := evalProgram(code)
+	if err != nil {
+		return nil, err
+	}
+
+	tactic := &sim.Tactic{}
+
+	res, err := i.Eval(qualifiedName(pkg, "ChooseCard"))
+	if err != nil {
+		return nil, errors.New("can't find proper ChooseCard definition")
+	}
+	chooseCard, ok := res.Interface().(func(game.State) game.CardType)
+	if !ok {
+		return nil, errors.New("can't find proper ChooseCard definition")
+	}
+	tactic.ChooseCard = chooseCard
+
+	// ChoosePath is optional.
+	if res, err := i.Eval(qualifiedName(pkg, "ChoosePath")); err == nil {
+		choosePath, ok := res.Interface().(func(game.State, []game.PathOption) int)
+		if !ok {
+			return nil, errors.New("ChoosePath has invalid signature")
+		}
+		tactic.ChoosePath = choosePath
+	}
+
+	// BuildDeck is optional.
+	if res, err := i.Eval(qualifiedName(pkg, "BuildDeck")); err == nil {
+		buildDeck, ok := res.Interface().(func(game.DraftOffer) game.Loadout)
+		if !ok {
+			return nil, errors.New("BuildDeck has invalid signature")
+		}
+		tactic.BuildDeck = buildDeck
+	}
+
+	// ChooseClass is optional.
+	if res, err := i.Eval(qualifiedName(pkg, "ChooseClass")); err == nil {
+		chooseClass, ok := res.Interface().(func() game.AvatarClass)
+		if !ok {
+			return nil, errors.New("ChooseClass has invalid signature")
+		}
+		tactic.ChooseClass = chooseClass
+	}
+
+	// ChoosePerk is optional.
+	if res, err := i.Eval(qualifiedName(pkg, "ChoosePerk")); err == nil {
+		choosePerk, ok := res.Interface().(func(game.State, []game.Perk) game.Perk)
+		if !ok {
+			return nil, errors.New("ChoosePerk has invalid signature")
+		}
+		tactic.ChoosePerk = choosePerk
+	}
+
+	// UsePotion is optional.
+	if res, err := i.Eval(qualifiedName(pkg, "UsePotion")); err == nil {
+		usePotion, ok := res.Interface().(func(game.State) game.PotionType)
+		if !ok {
+			return nil, errors.New("UsePotion has invalid signature")
+		}
+		tactic.UsePotion = usePotion
+	}
+
+	return tactic, nil
+}
+
+// LoadDungeonMaster evaluates the dungeon master program and collects its entry points.
+func LoadDungeonMaster(code string) (*sim.DungeonMaster, error) {
+	i, pkg, err := evalProgram(code)
+	if err != nil {
+		return nil, err
+	}
+
+	master := &sim.DungeonMaster{}
+
+	// Both ChooseCreep and ChooseIntent are optional.
+	if res, err := i.Eval(qualifiedName(pkg, "ChooseCreep")); err == nil {
+		chooseCreep, ok := res.Interface().(func(game.State, int, int) game.CreepType)
+		if !ok {
+			return nil, errors.New("ChooseCreep has invalid signature")
+		}
+		master.ChooseCreep = chooseCreep
+	}
+	if res, err := i.Eval(qualifiedName(pkg, "ChooseIntent")); err == nil {
+		chooseIntent, ok := res.Interface().(func(game.State, []game.CreepIntent) game.CreepIntent)
+		if !ok {
+			return nil, errors.New("ChooseIntent has invalid signature")
+		}
+		master.ChooseIntent = chooseIntent
+	}
+	if master.ChooseCreep == nil && master.ChooseIntent == nil {
+		return nil, errors.New("can't find ChooseCreep or ChooseIntent definition")
+	}
+
+	return master, nil
+}
+
+func inferPackage(s string) string {
+	newline := strings.IndexByte(s, '\n')
+	if newline == -1 {
+		return ""
+	}
+	line := s[:newline]
+	if !strings.HasPrefix(line, "package ") {
+		return ""
+	}
+	packageName := line[len("package "):]
+	return packageName
+}
+
+func qualifiedName(pkg, name string) string {
+	if pkg == "" {
+		return name
+	}
+	return pkg + "." + name
+}
@@ -0,0 +1,162 @@
+package sim
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// CampaignProgress is a persistent campaign state.
+// Cards and creeps are stored by their names to keep the saves compatible.
+type CampaignProgress struct {
+	// Chapter is an index of the next chapter to play.
+	Chapter int `json:"chapter"`
+
+	// Cards and Creeps are unlocked reward cards and creeps.
+	Cards  []string `json:"cards"`
+	Creeps []string `json:"creeps"`
+
+	// BestScores contains the best score for every played chapter.
+	BestScores []int `json:"bestScores"`
+}
+
+// NewCampaignProgress returns a progress of the campaign that was not started yet.
+func NewCampaignProgress() *CampaignProgress {
+	p := &CampaignProgress{}
+	for _, typ := range gamedata.CampaignCards {
+		p.Cards = append(p.Cards, typ.String())
+	}
+	return p
+}
+
+// ParseCampaignProgress decodes a saved campaign progress.
+// Empty data is decoded as a new campaign.
+func ParseCampaignProgress(data []byte) (*CampaignProgress, error) {
+	if len(data) == 0 {
+		return NewCampaignProgress(), nil
+	}
+	var p CampaignProgress
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	if p.Chapter < 0 || p.Chapter > len(gamedata.Chapters) {
+		return nil, fmt.Errorf("invalid chapter %d", p.Chapter)
+	}
+	return &p, nil
+}
+
+// Completed reports whether all campaign chapters are cleared.
+func (p *CampaignProgress) Completed() bool {
+	return p.Chapter >= len(gamedata.Chapters)
+}
+
+// RunCampaign plays the next campaign chapter and updates the progress.
+// Chapter rules override the corresponding config fields.
+// The updated progress is also reported via simstep.SaveProgress.
+func RunCampaign(config *Config, progress *CampaignProgress, tactic *Tactic) []simstep.Action {
+	if progress.Completed() {
+		return []simstep.Action{simstep.GreenLog{Message: "The campaign is completed!"}}
+	}
+	if progress.Chapter < 0 {
+		return []simstep.Action{simstep.RedLog{Message: fmt.Sprintf("Invalid campaign chapter %d", progress.Chapter)}}
+	}
+
+	chapter := &gamedata.Chapters[progress.Chapter]
+	chapterConfig := *config
+	chapterConfig.Rounds = chapter.Rounds
+	chapterConfig.Bosses = chapter.Bosses
+	chapterConfig.Intents = chapter.Intents
+	chapterConfig.MapMode = false
+	chapterConfig.Endless = false
+
+	runner := newRunner(&chapterConfig, tactic)
+	runner.campaign = newCampaignRun(progress, chapter)
+	runner.emitLogf("Chapter %d: %s", progress.Chapter+1, chapter.Name)
+	return runner.Run()
+}
+
+type campaignRun struct {
+	progress *CampaignProgress
+	chapter  *gamedata.Chapter
+
+	// creeps is a creep pool for the non-boss rounds.
+	creeps []game.CreepType
+
+	cards map[game.CardType]bool
+
+	cleared bool
+}
+
+func newCampaignRun(progress *CampaignProgress, chapter *gamedata.Chapter) *campaignRun {
+	c := &campaignRun{
+		progress: progress,
+		chapter:  chapter,
+		cards:    make(map[game.CardType]bool),
+	}
+	c.creeps = append(c.creeps, chapter.Creeps...)
+	for _, name := range progress.Creeps {
+		// Unknown names can come from the outdated saves.
+		typ, ok := parseCreepType(name)
+		if ok && !c.hasCreep(typ) {
+			c.creeps = append(c.creeps, typ)
+		}
+	}
+	for _, name := range progress.Cards {
+		if typ, ok := parseCardType(name); ok {
+			c.cards[typ] = true
+		}
+	}
+	return c
+}
+
+func (c *campaignRun) hasCreep(typ game.CreepType) bool {
+	for _, x := range c.creeps {
+		if x == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *runner) peekCampaignCreep(round int) game.CreepType {
+	if round == r.config.Rounds {
+		return r.campaign.chapter.Boss
+	}
+	creeps := r.campaign.creeps
+	return creeps[r.rand.Intn(len(creeps))]
+}
+
+func (r *runner) finishChapter() {
+	c := r.campaign
+	p := c.progress
+	chapter := c.chapter
+
+	for len(p.BestScores) <= p.Chapter {
+		p.BestScores = append(p.BestScores, 0)
+	}
+	if r.state.Score > p.BestScores[p.Chapter] {
+		p.BestScores[p.Chapter] = r.state.Score
+	}
+
+	if c.cleared {
+		r.emitGreenLogf("Chapter %q is cleared!", chapter.Name)
+		for _, typ := range chapter.UnlockCards {
+			r.emitGreenLogf("Unlocked %s card", typ.String())
+			p.Cards = append(p.Cards, typ.String())
+		}
+		for _, typ := range chapter.UnlockCreeps {
+			r.emitGreenLogf("Unlocked %s creep", typ.String())
+			p.Creeps = append(p.Creeps, typ.String())
+		}
+		p.Chapter++
+	}
+
+	data, err := json.Marshal(p)
+	if err != nil {
+		panic(err) // Should never happen
+	}
+	r.out = append(r.out, simstep.SaveProgress{Data: string(data)})
+}
@@ -0,0 +1,123 @@
+package sim
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestRunCampaign(t *testing.T) {
+	config := &Config{
+		AvatarHP: 200,
+		AvatarMP: 20,
+	}
+	tactic := &Tactic{
+		ChooseCard: func(s game.State) game.CardType {
+			return game.CardAttack
+		},
+	}
+
+	progress, err := ParseCampaignProgress(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for seed := int64(0); !progress.Completed(); seed++ {
+		if seed == 20 {
+			t.Fatalf("campaign is not completed after %d runs", seed)
+		}
+		config.Seed = seed
+		chapter := progress.Chapter
+		unlocked := append([]string(nil), progress.Cards...)
+
+		var saved *CampaignProgress
+		for _, a := range RunCampaign(config, progress, tactic) {
+			switch a := a.(type) {
+			case simstep.ChangeCardCount:
+				typ, _ := parseCardType(a.Name)
+				if !containsString(unlocked, typ.String()) {
+					t.Fatalf("seed=%d: got locked %s card", seed, a.Name)
+				}
+			case simstep.SaveProgress:
+				saved, err = ParseCampaignProgress([]byte(a.Data))
+				if err != nil {
+					t.Fatalf("seed=%d: %v", seed, err)
+				}
+			}
+		}
+		if !reflect.DeepEqual(saved, progress) {
+			t.Fatalf("seed=%d: saved progress mismatch:\nhave: %+v\nwant: %+v", seed, saved, progress)
+		}
+		if progress.Chapter == chapter {
+			continue
+		}
+		if progress.BestScores[chapter] == 0 {
+			t.Fatalf("seed=%d: no best score for chapter %d", seed, chapter)
+		}
+	}
+
+	if !containsString(progress.Cards, "Heal") || !containsString(progress.Creeps, "Mummy") {
+		t.Fatalf("missing unlocks: %+v", progress)
+	}
+
+	for _, chapter := range []int{-1, len(gamedata.Chapters) + 1} {
+		data := fmt.Sprintf(`{"chapter": %d}`, chapter)
+		if _, err := ParseCampaignProgress([]byte(data)); err == nil {
+			t.Errorf("chapter %d: invalid progress is accepted", chapter)
+		}
+	}
+	progress.Chapter = -1
+	if result := RunCampaign(config, progress, tactic); len(result) != 1 {
+		t.Errorf("invalid chapter is played: %v", result)
+	}
+
+	// A save can have all reward cards locked.
+	progress, err = ParseCampaignProgress([]byte(`{"chapter": 2}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	actions := RunCampaign(config, progress, tactic)
+	checkNoPanic(t, actions)
+	for _, a := range actions {
+		if a, ok := a.(simstep.ChangeCardCount); ok {
+			t.Fatalf("got locked %s card", a.Name)
+		}
+	}
+}
+
+func TestCampaignDraft(t *testing.T) {
+	config := &Config{
+		AvatarHP: 40,
+		AvatarMP: 20,
+		Draft:    true,
+	}
+	progress := NewCampaignProgress()
+	r := newRunner(config, &Tactic{})
+	r.campaign = newCampaignRun(progress, &gamedata.Chapters[0])
+
+	offer := r.draftOffer()
+	for typ := range offer.CardPrices {
+		if !containsString(progress.Cards, typ.String()) {
+			t.Errorf("locked %s card is offered", typ)
+		}
+	}
+	if _, ok := offer.CardPrices[game.CardStun]; !ok {
+		t.Errorf("unlocked Stun card is not offered")
+	}
+	loadout := game.Loadout{Cards: map[game.CardType]int{game.CardHeal: 1}}
+	if validateLoadout(&offer, &loadout) == nil {
+		t.Errorf("locked card loadout is accepted")
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, x := range list {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
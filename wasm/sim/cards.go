@@ -0,0 +1,197 @@
+package sim
+
+import (
+	"strings"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// effectCard applies the card effects described in the gamedata.
+// All cards are interpreted by this rule.
+type effectCard struct{ ruleBase }
+
+func (effectCard) OnCardPlayed(r *runner, play *cardPlay) {
+	for _, e := range gamedata.GetCardEffects(play.cardType) {
+		if play.canceled {
+			break
+		}
+		if !r.checkCondition(e.If) {
+			continue
+		}
+		r.applyCardEffect(play, e)
+	}
+}
+
+func (r *runner) checkCondition(cond gamedata.Condition) bool {
+	creep := &r.state.Creep
+	switch cond.Kind {
+	case gamedata.CondTrait:
+		return creep.Traits.Has(cond.Trait)
+	case gamedata.CondNoTrait:
+		return !creep.Traits.Has(cond.Trait)
+	case gamedata.CondNotStunned:
+		return !creep.IsStunned()
+	case gamedata.CondStunned:
+		return creep.IsStunned()
+	default:
+		return true
+	}
+}
+
+func (r *runner) applyCardEffect(play *cardPlay, e gamedata.CardEffect) {
+	creep := &r.state.Creep
+	avatar := &r.state.Avatar
+
+	switch e.Kind {
+	case gamedata.EffectDamage:
+		damage := r.effectAmount(play.card, e)
+		if avatar.Focused {
+			damage *= 2
+			r.setAvatarFocus(false)
+			r.emitLogf("Focus doubles the %s damage", play.cardType.String())
+		}
+		if play.combo != nil {
+			damage += damage * play.combo.DamagePercent / 100
+		}
+		attacker := r.avatarAttackStats(play.card)
+		switch r.rollHit(attacker, creep.CombatStats) {
+		case hitMiss:
+			// The rest of the card effects are canceled as well.
+			r.emitMissLogf("Your %s missed!", play.cardType.String())
+			play.canceled = true
+			return
+		case hitCritical:
+			damage = criticalDamage(attacker, damage)
+			r.emitCritLogf("Critical hit!")
+		}
+		play.damage += r.damageCreep(play.cardType, play.card.Element, damage)
+
+	case gamedata.EffectDrain:
+		r.avatarHeal(play.cardType.String(), play.damage)
+
+	case gamedata.EffectHeal:
+		r.avatarHeal(play.cardType.String(), r.effectAmount(play.card, e))
+
+	case gamedata.EffectStatus:
+		if e.Interrupt && creep.Intent == game.IntentDefend && !creep.IsStunned() {
+			r.emitRedLogf("%s blocked the %s", creep.Type.String(), statusName(e.Status))
+			return
+		}
+		r.applyStatus(e.Status, r.effectAmount(play.card, e))
+		if e.Interrupt && creep.Intent != game.IntentAttack {
+			r.emitLogf("%s %s is interrupted", creep.Type.String(), creep.Intent.String())
+			r.setCreepIntent(game.IntentAttack)
+		}
+
+	case gamedata.EffectGainMP:
+		gained := calculateHealed(r.effectAmount(play.card, e), avatar.MP, avatar.MaxMP)
+		avatar.MP += gained
+		r.out = append(r.out, simstep.UpdateMP{Delta: gained})
+		r.emitGreenLogf("Got %d MP from %s", gained, play.cardType.String())
+
+	case gamedata.EffectDrawCard:
+		for i := r.effectAmount(play.card, e); i > 0; i-- {
+			typ, ok := r.peekCard()
+			if !ok {
+				r.emitRedLogf("There are no cards to draw")
+				break
+			}
+			r.emitGreenLogf("Drew %s card", typ.String())
+			r.out = append(r.out, simstep.ChangeCardCount{
+				Name:  typ.String(),
+				Delta: 1,
+			})
+			changeDeckCardCount(r.state.Deck, typ, 1)
+		}
+
+	case gamedata.EffectDestroyMinion:
+		if creep.Minions > 0 {
+			creep.Minions--
+			r.emitLogf("%s destroys one of the %s minions", play.cardType.String(), creep.Type.String())
+		}
+
+	case gamedata.EffectParry:
+		// Parry is resolved during the creep move, see runTurn.
+
+	case gamedata.EffectRetreat:
+		r.emitLogf("Trying to retreat...")
+
+	case gamedata.EffectEvade:
+		play.evade = true
+		r.emitLogf("%s loses sight of you", creep.Type.String())
+
+	case gamedata.EffectReveal:
+		if !r.revealCreeps(r.effectAmount(play.card, e)) {
+			r.emitRedLogf("%s shows nothing but fog", play.cardType.String())
+			return
+		}
+		names := make([]string, len(r.state.Forecast))
+		for i, typ := range r.state.Forecast {
+			names[i] = typ.String()
+		}
+		r.out = append(r.out, simstep.SetForecast{Names: strings.Join(names, ", ")})
+		r.emitLogf("%s reveals the upcoming creeps: %s", play.cardType.String(), strings.Join(names, ", "))
+	}
+}
+
+func (r *runner) applyStatus(status gamedata.Status, value int) {
+	creep := &r.state.Creep
+	avatar := &r.state.Avatar
+	switch status {
+	case gamedata.StatusStun:
+		creep.Stun = value
+		if value == 1 {
+			r.emitLogf("%s is stunned for 1 turn", creep.Type.String())
+		} else {
+			r.emitLogf("%s is stunned for %d turns", creep.Type.String(), value)
+		}
+
+	case gamedata.StatusPoison:
+		r.setCreepPoison(value)
+		r.emitLogf("%s is poisoned for %d turns", creep.Type.String(), value)
+
+	case gamedata.StatusBlock:
+		r.setAvatarBlock(avatar.Block + value)
+		r.emitGreenLogf("Ready to block %d damage", avatar.Block)
+
+	case gamedata.StatusFocus:
+		r.setAvatarFocus(true)
+		r.emitGreenLogf("Focused on the next attack")
+	}
+}
+
+func (r *runner) setCreepPoison(turns int) {
+	r.state.Creep.Poison = turns
+	r.out = append(r.out, simstep.SetStatus{Target: "creep", Name: "Poison", Value: turns})
+}
+
+func (r *runner) setAvatarBlock(block int) {
+	r.state.Avatar.Block = block
+	r.out = append(r.out, simstep.SetStatus{Target: "avatar", Name: "Block", Value: block})
+}
+
+func (r *runner) setAvatarFocus(focused bool) {
+	r.state.Avatar.Focused = focused
+	value := 0
+	if focused {
+		value = 1
+	}
+	r.out = append(r.out, simstep.SetStatus{Target: "avatar", Name: "Focus", Value: value})
+}
+
+func statusName(status gamedata.Status) string {
+	switch status {
+	case gamedata.StatusStun:
+		return "stun"
+	case gamedata.StatusPoison:
+		return "poison"
+	case gamedata.StatusBlock:
+		return "block"
+	case gamedata.StatusFocus:
+		return "focus"
+	default:
+		return "status"
+	}
+}
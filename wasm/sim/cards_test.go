@@ -0,0 +1,163 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+func TestCardEffects(t *testing.T) {
+	config := &Config{
+		AvatarHP: 40,
+		AvatarMP: 20,
+		Rounds:   10,
+		Seed:     1,
+	}
+	r := newRunner(config, &Tactic{})
+	r.initWorld()
+	r.out = nil
+
+	r.state.Avatar.MP = 15
+	play := &cardPlay{cardType: game.CardRest, card: gamedata.GetCardStats(game.CardRest)}
+	r.applyCardEffect(play, gamedata.CardEffect{Kind: gamedata.EffectGainMP, Amount: 10})
+	if r.state.Avatar.MP != 20 {
+		t.Errorf("gain MP: have %d MP, want 20", r.state.Avatar.MP)
+	}
+
+	total := 0
+	for _, card := range r.state.Deck {
+		total += card.Count
+	}
+	r.applyCardEffect(play, gamedata.CardEffect{Kind: gamedata.EffectDrawCard, Amount: 2})
+	for _, card := range r.state.Deck {
+		total -= card.Count
+	}
+	if total != -2 {
+		t.Errorf("draw card: deck size changed by %d, want 2", -total)
+	}
+	r.peekableCards = nil
+	deck := cloneDeck(r.state.Deck)
+	r.applyCardEffect(play, gamedata.CardEffect{Kind: gamedata.EffectDrawCard, Amount: 2})
+	if !reflect.DeepEqual(deck, r.state.Deck) {
+		t.Errorf("draw card: the deck is changed without the reward cards")
+	}
+
+	r.state.Creep.Traits = game.CreepTraitList{game.TraitWeakToFire}
+	if !r.checkCondition(gamedata.Condition{Kind: gamedata.CondTrait, Trait: game.TraitWeakToFire}) {
+		t.Errorf("trait condition is not satisfied")
+	}
+	if r.checkCondition(gamedata.Condition{Kind: gamedata.CondNoTrait, Trait: game.TraitWeakToFire}) {
+		t.Errorf("no trait condition is satisfied")
+	}
+}
+
+func TestExpansionCards(t *testing.T) {
+	hasExpansionRewards := func(r *runner) bool {
+		for _, typ := range r.peekableCards {
+			if gamedata.IsExpansionCard(typ) {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("Rewards", func(t *testing.T) {
+		if hasExpansionRewards(newTestRunner(t, &Config{Seed: 1})) {
+			t.Errorf("expansion cards are rewarded in the classic mode")
+		}
+		if !hasExpansionRewards(newTestRunner(t, &Config{Seed: 1, ExpansionCards: true})) {
+			t.Errorf("expansion cards are not rewarded when enabled")
+		}
+	})
+
+	t.Run("Draft", func(t *testing.T) {
+		offer := newTestRunner(t, &Config{Seed: 1}).draftOffer()
+		if _, ok := offer.CardPrices[game.CardBlock]; ok {
+			t.Errorf("expansion cards are offered in the classic mode")
+		}
+		loadout := game.Loadout{Cards: map[game.CardType]int{game.CardBlock: 1}}
+		if validateLoadout(&offer, &loadout) == nil {
+			t.Errorf("expansion card loadout is accepted in the classic mode")
+		}
+		offer = newTestRunner(t, &Config{Seed: 1, ExpansionCards: true}).draftOffer()
+		if _, ok := offer.CardPrices[game.CardBlock]; !ok {
+			t.Errorf("expansion cards are not offered when enabled")
+		}
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1})
+		r.applyStatus(gamedata.StatusBlock, 3)
+		if have := r.avatarDamage(5); have != 2 {
+			t.Errorf("blocked hit: have %d damage, want 2", have)
+		}
+		if have := r.avatarDamage(5); have != 5 {
+			t.Errorf("next hit: have %d damage, want 5", have)
+		}
+	})
+
+	t.Run("FocusDrain", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1})
+		r.state.Avatar.HP = 20
+		r.applyStatus(gamedata.StatusFocus, 1)
+		play := &cardPlay{cardType: game.CardDrain, card: gamedata.GetCardStats(game.CardDrain)}
+		r.applyCardEffect(play, gamedata.CardEffect{Kind: gamedata.EffectDamage, Amount: 3})
+		r.applyCardEffect(play, gamedata.CardEffect{Kind: gamedata.EffectDrain})
+		if r.state.Creep.HP != 4 {
+			t.Errorf("creep: have %d HP, want 4", r.state.Creep.HP)
+		}
+		if r.state.Avatar.HP != 26 {
+			t.Errorf("avatar: have %d HP, want 26", r.state.Avatar.HP)
+		}
+		if r.state.Avatar.Focused {
+			t.Errorf("focus is not consumed")
+		}
+	})
+
+	t.Run("Poison", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCard(game.CardRest))
+		r.applyStatus(gamedata.StatusPoison, 3)
+		for i := 0; i < 4; i++ {
+			r.runTurn()
+		}
+		if r.state.Creep.HP != 10-3*gamedata.PoisonDamage {
+			t.Errorf("have %d HP, want %d", r.state.Creep.HP, 10-3*gamedata.PoisonDamage)
+		}
+	})
+
+	t.Run("SmokeBomb", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCard(game.CardSmokeBomb))
+		changeDeckCardCount(r.state.Deck, game.CardSmokeBomb, 1)
+		r.runTurn()
+		if r.state.Avatar.HP != 40 || r.state.Round != 2 {
+			t.Errorf("smoke bomb retreat: have %d HP at round %d", r.state.Avatar.HP, r.state.Round)
+		}
+		r.runTurn()
+		if r.state.Round != 2 {
+			t.Errorf("unavailable smoke bomb caused a retreat")
+		}
+	})
+
+	t.Run("ScrollOfInsight", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1})
+		if !r.revealCreeps(2) || len(r.state.Forecast) != 2 {
+			t.Fatalf("forecast is not filled: %v", r.state.Forecast)
+		}
+		forecast := append([]game.CreepType(nil), r.state.Forecast...)
+		for _, want := range forecast {
+			if have := r.peekCreep(r.state.Round + 1); have != want {
+				t.Errorf("peek creep: have %s, want %s", have, want)
+			}
+		}
+		if len(r.state.Forecast) != 0 {
+			t.Errorf("forecast is not consumed")
+		}
+
+		r = newTestRunner(t, &Config{Seed: 1, MapMode: true})
+		if r.revealCreeps(2) {
+			t.Errorf("map mode creeps are revealed")
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// selectClass returns the avatar class for this run.
+// Tactic selection has a priority over the config.
+func (r *runner) selectClass() game.AvatarClass {
+	if r.tactic.ChooseClass != nil {
+		return r.tactic.ChooseClass()
+	}
+	return r.config.Class
+}
+
+func (r *runner) applyClass(class game.AvatarClass) {
+	stats, ok := gamedata.GetClassStats(class)
+	if !ok {
+		if class != game.ClassNone {
+			r.emitRedLogf("Unknown avatar class %s", class.String())
+			r.badMoves++
+		}
+		return
+	}
+
+	avatar := &r.state.Avatar
+	avatar.Class = class
+	r.out = append(r.out, simstep.UpdateHP{Delta: stats.AvatarStats.MaxHP - avatar.HP})
+	r.out = append(r.out, simstep.UpdateMP{Delta: stats.AvatarStats.MaxMP - avatar.MP})
+	avatar.AvatarStats = stats.AvatarStats
+	avatar.HP = stats.AvatarStats.MaxHP
+	avatar.MP = stats.AvatarStats.MaxMP
+
+	for _, typ := range sortedCardTypes(stats.Cards) {
+		n := stats.Cards[typ]
+		r.out = append(r.out, simstep.ChangeCardCount{
+			Name:  typ.String(),
+			Delta: n,
+		})
+		changeDeckCardCount(r.state.Deck, typ, n)
+	}
+
+	card := r.state.Deck[stats.SignatureCard]
+	card.Count = -1
+	r.state.Deck[stats.SignatureCard] = card
+	r.out = append(r.out, simstep.SetCardCount{
+		Name:  stats.SignatureCard.String(),
+		Count: -1,
+	})
+
+	r.out = append(r.out, simstep.Meta{Key: "class", Value: class.String()})
+}
@@ -0,0 +1,45 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+func TestRunClasses(t *testing.T) {
+	classes := []game.AvatarClass{game.ClassWarrior, game.ClassMage, game.ClassRogue}
+	signatureCards := []game.CardType{game.CardShieldBash, game.CardChainLightning, game.CardBackstab}
+
+	for i, class := range classes {
+		config := &Config{
+			AvatarHP: 40,
+			AvatarMP: 20,
+			Rounds:   10,
+			Class:    class,
+		}
+		signature := signatureCards[i]
+		chooseCard := func(s game.State) game.CardType {
+			if s.Avatar.Class != class {
+				t.Fatalf("%s: avatar class is %s", class, s.Avatar.Class)
+			}
+			for _, typ := range signatureCards {
+				count := s.Deck[typ].Count
+				if typ == signature && count != -1 {
+					t.Fatalf("%s: %s count is %d", class, typ, count)
+				}
+				if typ != signature && count != 0 {
+					t.Fatalf("%s: got other class card %s", class, typ)
+				}
+			}
+			if s.Can(signature) {
+				return signature
+			}
+			return game.CardAttack
+		}
+
+		for seed := int64(0); seed < 10; seed++ {
+			config.Seed = seed
+			Run(config, chooseCard)
+		}
+	}
+}
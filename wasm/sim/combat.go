@@ -0,0 +1,42 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+// hitResult is an outcome of the attack rolls.
+type hitResult int
+
+const (
+	hitNormal hitResult = iota
+	hitMiss
+	hitCritical
+)
+
+// rollHit resolves the evasion and critical hit rolls of the attack.
+// No rolls are done unless the combat rolls are enabled.
+func (r *runner) rollHit(attacker, defender game.CombatStats) hitResult {
+	if !r.config.CombatRolls {
+		return hitNormal
+	}
+	evasion := defender.Evasion - attacker.Accuracy
+	if evasion > 0 && r.rand.Intn(100) < evasion {
+		return hitMiss
+	}
+	if attacker.CritChance > 0 && r.rand.Intn(100) < attacker.CritChance {
+		return hitCritical
+	}
+	return hitNormal
+}
+
+// avatarAttackStats returns the avatar combat stats with the card bonuses applied.
+func (r *runner) avatarAttackStats(card game.CardStats) game.CombatStats {
+	stats := r.state.Avatar.CombatStats
+	stats.Accuracy += card.Accuracy
+	stats.CritChance += card.CritChance
+	return stats
+}
+
+func criticalDamage(attacker game.CombatStats, damage int) int {
+	return damage + damage*attacker.CritBonus/100
+}
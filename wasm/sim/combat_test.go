@@ -0,0 +1,128 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestResistances(t *testing.T) {
+	tests := []struct {
+		resist int
+		intent game.CreepIntent
+		damage int
+		want   int
+	}{
+		{0, game.IntentAttack, 5, 5},
+		{-100, game.IntentAttack, 5, 10},
+		{50, game.IntentAttack, 5, 3},
+		{100, game.IntentAttack, 5, 0},
+		{200, game.IntentAttack, 5, 0},
+		{-100, game.IntentDefend, 5, 5},
+		{0, game.IntentDefend, 5, 2},
+	}
+
+	for _, test := range tests {
+		creep := game.Creep{Intent: test.intent}
+		creep.Resistances = game.Resistances{game.ElementFrost: test.resist}
+		have := creep.EffectiveDamage(game.ElementFrost, test.damage)
+		if have != test.want {
+			t.Errorf("EffectiveDamage(%d%%, %s, %d): have %d, want %d",
+				test.resist, test.intent, test.damage, have, test.want)
+		}
+		if have := creep.EffectiveDamage(game.ElementFire, 4); test.intent == game.IntentAttack && have != 4 {
+			t.Errorf("unresisted element damage: have %d, want 4", have)
+		}
+	}
+
+	config := &Config{
+		AvatarHP: 40,
+		AvatarMP: 20,
+		Rounds:   10,
+		Seed:     1,
+	}
+	r := newRunner(config, &Tactic{})
+	r.initWorld()
+	r.state.Creep = newCreep(game.CreepMummy, gamedata.GetCreepStats(game.CreepMummy))
+	r.damageCreep(game.CardFirebolt, game.ElementFire, 4)
+	r.damageCreep(game.CardAttack, game.ElementPhysical, 4)
+	if want := 18 - 8 - 4; r.state.Creep.HP != want {
+		t.Errorf("mummy HP: have %d, want %d", r.state.Creep.HP, want)
+	}
+}
+
+func TestRollHit(t *testing.T) {
+	attacker := game.CombatStats{Accuracy: 10, CritChance: 20, CritBonus: 50}
+	defender := game.CombatStats{Evasion: 30}
+
+	tests := []struct {
+		rolls []int
+		want  hitResult
+	}{
+		{[]int{19}, hitMiss},
+		{[]int{20, 19}, hitCritical},
+		{[]int{20, 20}, hitNormal},
+		{[]int{99, 99}, hitNormal},
+	}
+
+	for _, test := range tests {
+		r := newRunner(&Config{CombatRolls: true}, &Tactic{})
+		r.rand = &scriptedRolls{rolls: test.rolls}
+		if have := r.rollHit(attacker, defender); have != test.want {
+			t.Errorf("rollHit(%v): have %d, want %d", test.rolls, have, test.want)
+		}
+	}
+
+	r := newRunner(&Config{}, &Tactic{})
+	r.rand = &scriptedRolls{}
+	if have := r.rollHit(attacker, defender); have != hitNormal {
+		t.Errorf("rollHit with disabled combat rolls: have %d, want hitNormal", have)
+	}
+
+	if have := criticalDamage(attacker, 5); have != 7 {
+		t.Errorf("criticalDamage(50%%, 5): have %d, want 7", have)
+	}
+}
+
+func TestRunCombatRolls(t *testing.T) {
+	chooseCard := func(s game.State) game.CardType {
+		return game.CardAttack
+	}
+	countLogs := func(out []simstep.Action) (misses, crits int) {
+		for _, a := range out {
+			switch a.(type) {
+			case simstep.MissLog:
+				misses++
+			case simstep.CritLog:
+				crits++
+			}
+		}
+		return misses, crits
+	}
+
+	totalMisses, totalCrits := 0, 0
+	for seed := int64(0); seed < 10; seed++ {
+		config := &Config{
+			AvatarHP: 100,
+			AvatarMP: 20,
+			Rounds:   10,
+			Seed:     seed,
+		}
+		misses, crits := countLogs(Run(config, chooseCard))
+		if misses != 0 || crits != 0 {
+			t.Fatalf("seed=%d: combat rolls are disabled, but got %d misses and %d crits", seed, misses, crits)
+		}
+		config.CombatRolls = true
+		misses, crits = countLogs(Run(config, chooseCard))
+		totalMisses += misses
+		totalCrits += crits
+	}
+	if totalMisses == 0 {
+		t.Errorf("combat rolls are enabled, but there are no misses")
+	}
+	if totalCrits == 0 {
+		t.Errorf("combat rolls are enabled, but there are no critical hits")
+	}
+}
@@ -0,0 +1,57 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+// matchCombo returns a combo that is completed by the card being played.
+// The combo condition is checked before the card effects are applied.
+func (r *runner) matchCombo(typ game.CardType) *gamedata.Combo {
+	for i := range gamedata.Combos {
+		combo := &gamedata.Combos[i]
+		if !r.comboMatches(combo, typ) {
+			continue
+		}
+		if r.checkCondition(combo.If) {
+			return combo
+		}
+	}
+	return nil
+}
+
+func (r *runner) comboMatches(combo *gamedata.Combo, typ game.CardType) bool {
+	prefix := combo.Cards[:len(combo.Cards)-1]
+	if combo.Cards[len(prefix)] != typ || len(r.state.LastCards) < len(prefix) {
+		return false
+	}
+	lastCards := r.state.LastCards[len(r.state.LastCards)-len(prefix):]
+	for i, cardType := range prefix {
+		if lastCards[i] != cardType {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *runner) applyComboEffects(play *cardPlay) {
+	for _, e := range play.combo.Effects {
+		if play.canceled {
+			break
+		}
+		if !r.checkCondition(e.If) {
+			continue
+		}
+		r.applyCardEffect(play, e)
+	}
+}
+
+// rememberCard records a played card to the game.State.LastCards.
+// A new slice is allocated to avoid sharing it with the tactic state copies.
+func (r *runner) rememberCard(typ game.CardType) {
+	lastCards := r.state.LastCards
+	if len(lastCards) == gamedata.MaxComboLength {
+		lastCards = lastCards[1:]
+	}
+	r.state.LastCards = append(append([]game.CardType(nil), lastCards...), typ)
+}
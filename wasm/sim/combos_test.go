@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+func TestCombos(t *testing.T) {
+	t.Run("Table", func(t *testing.T) {
+		for _, combo := range gamedata.Combos {
+			if len(combo.Cards) < 2 || len(combo.Cards) > gamedata.MaxComboLength+1 {
+				t.Errorf("%s: bad cards sequence length %d", combo.Name, len(combo.Cards))
+			}
+		}
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1})
+		r.state.LastCards = []game.CardType{game.CardRest, game.CardParry}
+		if combo := r.matchCombo(game.CardAttack); combo == nil || combo.Name != "Riposte" {
+			t.Errorf("Parry+Attack: have %v, want Riposte", combo)
+		}
+		if combo := r.matchCombo(game.CardPowerAttack); combo != nil {
+			t.Errorf("Parry+PowerAttack: have %s, want no combo", combo.Name)
+		}
+		r.state.LastCards = []game.CardType{game.CardStun}
+		if combo := r.matchCombo(game.CardPowerAttack); combo != nil {
+			t.Errorf("execute matched a creep that is not stunned")
+		}
+		r.state.Creep.Stun = 1
+		if combo := r.matchCombo(game.CardPowerAttack); combo == nil || combo.Name != "Execute" {
+			t.Errorf("Stun+PowerAttack: have %v, want Execute", combo)
+		}
+	})
+
+	t.Run("LastCards", func(t *testing.T) {
+		cards := []game.CardType{game.CardRest, game.CardParry, game.CardAttack}
+		turn := 0
+		r := newTestRunner(t, &Config{Seed: 1}, withChooseCard(func(game.State) game.CardType {
+			turn++
+			return cards[turn-1]
+		}))
+		changeDeckCardCount(r.state.Deck, game.CardParry, 1)
+		for range cards {
+			r.runTurn()
+		}
+		want := cards[len(cards)-gamedata.MaxComboLength:]
+		if len(r.state.LastCards) != len(want) {
+			t.Fatalf("have %v, want %v", r.state.LastCards, want)
+		}
+		for i := range want {
+			if r.state.LastCards[i] != want[i] {
+				t.Fatalf("have %v, want %v", r.state.LastCards, want)
+			}
+		}
+		r.nextRound()
+		if len(r.state.LastCards) != 0 {
+			t.Errorf("last cards are not cleared after the round")
+		}
+	})
+
+	t.Run("Bonus", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1})
+		play := &cardPlay{cardType: game.CardAttack, card: gamedata.GetCardStats(game.CardAttack)}
+		play.combo = &gamedata.Combos[0]
+		r.applyCardEffect(play, gamedata.CardEffect{Kind: gamedata.EffectDamage, Amount: 3})
+		if r.state.Creep.HP != 4 {
+			t.Errorf("riposte: have %d HP, want 4", r.state.Creep.HP)
+		}
+
+		r = newTestRunner(t, &Config{Seed: 1, Combos: true})
+		r.state.LastCards = []game.CardType{game.CardBlock}
+		play = &cardPlay{cardType: game.CardAttack, card: gamedata.GetCardStats(game.CardAttack)}
+		r.runAvatarAction(play)
+		if play.combo == nil || !r.state.Creep.IsStunned() {
+			t.Errorf("shield slam didn't stun the creep")
+		}
+	})
+}
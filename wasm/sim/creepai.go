@@ -0,0 +1,128 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// creepAI selects the creep intent for its next move.
+// It's called right after the creep performs its current intent.
+type creepAI func(r *runner, creep *game.Creep) game.CreepIntent
+
+// creepAIs contains scripted boss behavior.
+// Other creeps use regularAI when intents are enabled and always attack otherwise.
+var creepAIs = map[game.CreepType]creepAI{
+	game.CreepDragon: bossAI,
+}
+
+func (r *runner) nextCreepIntent() game.CreepIntent {
+	creep := &r.state.Creep
+	if r.master != nil && r.master.ChooseIntent != nil {
+		if _, ok := creepAIs[creep.Type]; ok && r.config.Bosses {
+			updateEnraged(r, creep)
+		}
+		return r.masterIntent(creep)
+	}
+	if r.config.Bosses {
+		if ai, ok := creepAIs[creep.Type]; ok {
+			return ai(r, creep)
+		}
+	}
+	if r.config.Intents {
+		return regularAI(r, creep)
+	}
+	return game.IntentAttack
+}
+
+// initCreepIntent selects the first move intent of a newly spawned creep.
+// Bosses use their AI even if the regular creep intents are disabled.
+func (r *runner) initCreepIntent() {
+	r.state.Creep.Intent = r.nextCreepIntent()
+}
+
+func (r *runner) emitCreepIntent() {
+	if intent := r.state.Creep.Intent; intent != game.IntentAttack {
+		r.out = append(r.out, simstep.SetCreepIntent{Name: intent.String()})
+	}
+}
+
+func (r *runner) setCreepIntent(intent game.CreepIntent) {
+	creep := &r.state.Creep
+	if creep.Intent == intent {
+		return
+	}
+	creep.Intent = intent
+	r.out = append(r.out, simstep.SetCreepIntent{Name: intent.String()})
+}
+
+func bossAI(r *runner, creep *game.Creep) game.CreepIntent {
+	boss, _ := gamedata.GetBossStats(creep.Type)
+
+	updateEnraged(r, creep)
+
+	if mustDeliverCharge(creep) {
+		return game.IntentHeavyAttack
+	}
+
+	roll := r.rand.Intn(100)
+	switch {
+	case creep.HP*10 < creep.MaxHP*3 && roll >= 75:
+		return game.IntentHeal
+	case canSummon(creep, boss) && roll >= 80:
+		return game.IntentSummon
+	case roll >= 55:
+		return game.IntentCharge
+	default:
+		return game.IntentAttack
+	}
+}
+
+func regularAI(r *runner, creep *game.Creep) game.CreepIntent {
+	weights := gamedata.GetCreepIntents(creep.Type)
+	total := 0
+	for _, w := range weights {
+		total += w.Weight
+	}
+	if total == 0 {
+		return game.IntentAttack
+	}
+
+	intent := game.IntentAttack
+	roll := r.rand.Intn(total)
+	for _, w := range weights {
+		if roll < w.Weight {
+			intent = w.Intent
+			break
+		}
+		roll -= w.Weight
+	}
+
+	if intent == game.IntentFlee && !canFlee(creep) {
+		return game.IntentAttack
+	}
+	return intent
+}
+
+// mustDeliverCharge reports whether the creep charged an attack during its last move.
+// Charged attack is always delivered.
+func mustDeliverCharge(creep *game.Creep) bool {
+	return creep.Intent == game.IntentCharge
+}
+
+func canSummon(creep *game.Creep, boss gamedata.BossStats) bool {
+	return creep.Minions < boss.MaxMinions
+}
+
+// canFlee reports whether the creep is allowed to flee.
+// Creeps don't flee until they're wounded.
+func canFlee(creep *game.Creep) bool {
+	return !creep.IsFull()
+}
+
+func updateEnraged(r *runner, creep *game.Creep) {
+	if !creep.Enraged && creep.HP*2 < creep.MaxHP {
+		creep.Enraged = true
+		r.emitRedLogf("%s is enraged!", creep.Type.String())
+	}
+}
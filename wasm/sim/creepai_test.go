@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+)
+
+func TestRunBosses(t *testing.T) {
+	firstCharges := 0
+	for seed := int64(0); seed < 20; seed++ {
+		config := &Config{
+			AvatarHP: 200,
+			AvatarMP: 20,
+			Rounds:   1,
+			Seed:     seed,
+			Bosses:   true,
+		}
+		var intents []game.CreepIntent
+		chooseCard := func(s game.State) game.CardType {
+			intents = append(intents, s.Creep.Intent)
+			return game.CardAttack
+		}
+		Run(config, chooseCard)
+
+		for i := 1; i < len(intents); i++ {
+			if intents[i-1] == game.IntentCharge && intents[i] != game.IntentHeavyAttack {
+				t.Fatalf("seed=%d: charge is followed by %s", seed, intents[i])
+			}
+		}
+		if len(intents) != 0 && intents[0] == game.IntentCharge {
+			firstCharges++
+		}
+	}
+	if firstCharges == 0 {
+		t.Errorf("the first boss intent is not selected by the boss AI")
+	}
+}
+
+func TestRunIntents(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		config := &Config{
+			AvatarHP: 40,
+			AvatarMP: 20,
+			Rounds:   10,
+			Seed:     seed,
+			Intents:  true,
+		}
+		seen := make(map[game.CreepIntent]bool)
+		chooseCard := func(s game.State) game.CardType {
+			seen[s.Creep.Intent] = true
+			if s.Creep.Intent == game.IntentFlee && s.Creep.IsFull() {
+				t.Fatalf("seed=%d: %s is going to flee with full HP", seed, s.Creep.Type)
+			}
+			if s.Creep.Intent == game.IntentHeavyAttack && s.Can(game.CardStun) {
+				return game.CardStun
+			}
+			return game.CardAttack
+		}
+
+		firstResult := Run(config, chooseCard)
+		secondResult := Run(config, chooseCard)
+		if !reflect.DeepEqual(firstResult, secondResult) {
+			t.Errorf("seed=%d different results", seed)
+		}
+		if !seen[game.IntentAttack] {
+			t.Errorf("seed=%d: no attack intents", seed)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package sim
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// runDraft asks the tactic to build a starting deck and applies the result.
+// Invalid loadouts are rejected and the default deck is used instead.
+func (r *runner) runDraft() {
+	var loadout game.Loadout
+	if r.tactic.BuildDeck != nil {
+		loadout = r.tactic.BuildDeck(r.draftOffer())
+	}
+
+	// Tactic could modify the offer, so we validate against a fresh copy.
+	offer := r.draftOffer()
+	if err := validateLoadout(&offer, &loadout); err != nil {
+		r.emitRedLogf("Invalid loadout: %v", err)
+		r.badMoves++
+		loadout = game.Loadout{}
+	}
+	r.applyLoadout(&loadout)
+	r.out = append(r.out, simstep.Meta{Key: "loadout", Value: formatLoadout(&loadout)})
+}
+
+func (r *runner) draftOffer() game.DraftOffer {
+	return gamedata.GetDraftOffer(r.isAvailableCard)
+}
+
+func (r *runner) applyLoadout(loadout *game.Loadout) {
+	avatar := &r.state.Avatar
+
+	for _, typ := range sortedCardTypes(loadout.Cards) {
+		n := loadout.Cards[typ]
+		if n == 0 {
+			continue
+		}
+		r.out = append(r.out, simstep.ChangeCardCount{
+			Name:  typ.String(),
+			Delta: n,
+		})
+		changeDeckCardCount(r.state.Deck, typ, n)
+	}
+
+	for _, typ := range loadout.Drop {
+		r.out = append(r.out, simstep.DropCard{Name: typ.String()})
+		card := r.state.Deck[typ]
+		card.Count = 0
+		r.state.Deck[typ] = card
+	}
+
+	if loadout.ExtraHP != 0 {
+		avatar.MaxHP += loadout.ExtraHP
+		avatar.HP += loadout.ExtraHP
+		r.out = append(r.out, simstep.UpdateHP{Delta: loadout.ExtraHP})
+	}
+	if loadout.ExtraMP != 0 {
+		avatar.MaxMP += loadout.ExtraMP
+		avatar.MP += loadout.ExtraMP
+		r.out = append(r.out, simstep.UpdateMP{Delta: loadout.ExtraMP})
+	}
+}
+
+func validateLoadout(offer *game.DraftOffer, loadout *game.Loadout) error {
+	// Refunds are collected first, so the dropped cards can pay for the rest.
+	remaining := offer.Budget
+	dropped := make(map[game.CardType]bool, len(loadout.Drop))
+	for _, typ := range loadout.Drop {
+		refund, ok := offer.DropRefunds[typ]
+		if !ok {
+			return fmt.Errorf("%s can't be dropped", typ.String())
+		}
+		if dropped[typ] {
+			return fmt.Errorf("%s is dropped twice", typ.String())
+		}
+		dropped[typ] = true
+		remaining += refund
+	}
+
+	// spend checks the amount against the remaining budget before
+	// multiplying it, so huge amounts can't overflow the cost.
+	spend := func(what string, n, price int) error {
+		if n < 0 {
+			return fmt.Errorf("negative %s count", what)
+		}
+		if n > remaining || n*price > remaining {
+			return fmt.Errorf("%d %s exceed the budget of %d", n, what, offer.Budget)
+		}
+		remaining -= n * price
+		return nil
+	}
+
+	for _, typ := range sortedCardTypes(loadout.Cards) {
+		price, ok := offer.CardPrices[typ]
+		if !ok {
+			return fmt.Errorf("%s can't be drafted", typ.String())
+		}
+		if err := spend(typ.String(), loadout.Cards[typ], price); err != nil {
+			return err
+		}
+	}
+	if err := spend("extra HP", loadout.ExtraHP, offer.HPPrice); err != nil {
+		return err
+	}
+	return spend("extra MP", loadout.ExtraMP, offer.MPPrice)
+}
+
+func formatLoadout(loadout *game.Loadout) string {
+	var parts []string
+	for _, typ := range sortedCardTypes(loadout.Cards) {
+		if n := loadout.Cards[typ]; n != 0 {
+			parts = append(parts, fmt.Sprintf("%s x%d", typ.String(), n))
+		}
+	}
+	for _, typ := range loadout.Drop {
+		parts = append(parts, "-"+typ.String())
+	}
+	if loadout.ExtraHP != 0 {
+		parts = append(parts, fmt.Sprintf("+%d MaxHP", loadout.ExtraHP))
+	}
+	if loadout.ExtraMP != 0 {
+		parts = append(parts, fmt.Sprintf("+%d MaxMP", loadout.ExtraMP))
+	}
+	if len(parts) == 0 {
+		return "default"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sortedCardTypes(cards map[game.CardType]int) []game.CardType {
+	types := make([]game.CardType, 0, len(cards))
+	for typ := range cards {
+		types = append(types, typ)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"math"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestValidateLoadout(t *testing.T) {
+	offer := &game.DraftOffer{
+		Budget:      10,
+		CardPrices:  map[game.CardType]int{game.CardStun: 3, game.CardHeal: 5},
+		DropRefunds: map[game.CardType]int{game.CardMagicArrow: 6},
+		HPPrice:     1,
+		MPPrice:     2,
+	}
+
+	tests := []struct {
+		loadout game.Loadout
+		valid   bool
+	}{
+		{game.Loadout{}, true},
+		{game.Loadout{ExtraHP: 10}, true},
+		{game.Loadout{ExtraHP: 11}, false},
+		{game.Loadout{ExtraHP: 4, ExtraMP: 3}, true},
+		{game.Loadout{ExtraHP: -1}, false},
+		{game.Loadout{Cards: map[game.CardType]int{game.CardHeal: 2}}, true},
+		{game.Loadout{Cards: map[game.CardType]int{game.CardHeal: 3}}, false},
+		{game.Loadout{Cards: map[game.CardType]int{game.CardStun: -1}}, false},
+		{game.Loadout{Cards: map[game.CardType]int{game.CardParry: 1}}, false},
+		{
+			game.Loadout{
+				Cards: map[game.CardType]int{game.CardStun: 2, game.CardHeal: 2},
+				Drop:  []game.CardType{game.CardMagicArrow},
+			},
+			true,
+		},
+		{game.Loadout{Drop: []game.CardType{game.CardMagicArrow, game.CardMagicArrow}}, false},
+		{game.Loadout{Drop: []game.CardType{game.CardAttack}}, false},
+		{game.Loadout{Drop: []game.CardType{game.CardMagicArrow}, ExtraHP: 16}, true},
+
+		// The cost can't overflow.
+		{game.Loadout{ExtraMP: math.MaxInt64/2 + 1}, false},
+		{game.Loadout{ExtraHP: math.MaxInt64}, false},
+		{game.Loadout{Cards: map[game.CardType]int{game.CardStun: math.MaxInt64/3 + 1}}, false},
+		{game.Loadout{Cards: map[game.CardType]int{game.CardHeal: math.MaxInt64/5 + 1}}, false},
+		{
+			game.Loadout{
+				Cards:   map[game.CardType]int{game.CardStun: math.MaxInt64},
+				ExtraHP: math.MaxInt64,
+				ExtraMP: math.MaxInt64,
+			},
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		err := validateLoadout(offer, &test.loadout)
+		if (err == nil) != test.valid {
+			t.Errorf("%+v: have err=%v, want valid=%v", test.loadout, err, test.valid)
+		}
+	}
+}
+
+func TestRunDraft(t *testing.T) {
+	config := &Config{
+		AvatarHP: 40,
+		AvatarMP: 20,
+		Rounds:   10,
+		Draft:    true,
+	}
+	tactic := &Tactic{
+		BuildDeck: func(offer game.DraftOffer) game.Loadout {
+			return game.Loadout{
+				Cards:   map[game.CardType]int{game.CardStun: 2},
+				Drop:    []game.CardType{game.CardMagicArrow},
+				ExtraHP: 4,
+			}
+		},
+		ChooseCard: func(s game.State) game.CardType {
+			if s.Turn == 1 {
+				if s.Deck[game.CardStun].Count != 2 || s.Deck[game.CardMagicArrow].Count != 0 {
+					t.Fatalf("loadout cards are not applied")
+				}
+				if s.Avatar.HP != 44 || s.Avatar.MaxHP != 44 {
+					t.Fatalf("loadout stats are not applied")
+				}
+			}
+			return game.CardRetreat
+		},
+	}
+
+	result := RunTactic(config, tactic)
+	want := simstep.Meta{Key: "loadout", Value: "Stun x2, -MagicArrow, +4 MaxHP"}
+	found := false
+	for _, a := range result {
+		if a == want {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("loadout metadata is not recorded")
+	}
+}
@@ -0,0 +1,372 @@
+package sim
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// maxDuelTurns is a duel length limit; a duel that lasts longer is a draw.
+const maxDuelTurns = 100
+
+type DuelConfig struct {
+	AvatarHP int
+	AvatarMP int
+	Seed     int64
+
+	// Simultaneous makes both duelists choose their cards at the same time.
+	// Otherwise duelists act in turns and the first duelist goes first.
+	Simultaneous bool
+
+	// IllegalMoves selects how the illegal card moves are handled.
+	// The zero value is IllegalMoveSkip.
+	IllegalMoves IllegalMovePolicy
+}
+
+// RunDuel runs a tactic versus tactic game.
+// Only Tactic.ChooseCard is used in duels.
+func RunDuel(config *DuelConfig, first, second *Tactic) []simstep.Action {
+	runner := newDuelRunner(config, first, second)
+	return runner.Run()
+}
+
+type duelist struct {
+	name     string
+	tactic   *Tactic
+	avatar   game.Avatar
+	deck     map[game.CardType]game.Card
+	stun     int
+	parry    bool
+	badMoves int
+}
+
+type duelRunner struct {
+	simBase
+
+	config *DuelConfig
+	turn   int
+	sides  [2]duelist
+}
+
+func newDuelRunner(config *DuelConfig, first, second *Tactic) *duelRunner {
+	r := &duelRunner{
+		simBase: simBase{rand: rand.New(rand.NewSource(config.Seed))},
+		config:  config,
+		turn:    1,
+	}
+	for i, tactic := range [2]*Tactic{first, second} {
+		avatarStats := game.AvatarStats{
+			MaxHP: config.AvatarHP,
+			MaxMP: config.AvatarMP,
+		}
+		r.sides[i] = duelist{
+			name:   fmt.Sprintf("Duelist %d", i+1),
+			tactic: tactic,
+			avatar: game.Avatar{
+				HP:          avatarStats.MaxHP,
+				MP:          avatarStats.MaxMP,
+				Level:       1,
+				AvatarStats: avatarStats,
+			},
+			deck: newDuelDeck(),
+		}
+	}
+	return r
+}
+
+func newDuelDeck() map[game.CardType]game.Card {
+	deck := make(map[game.CardType]game.Card, len(gamedata.Cards))
+	for typ, cardStats := range gamedata.Cards {
+		card := game.Card{
+			Type:      typ,
+			CardStats: cardStats,
+			Count:     gamedata.DuelCards[typ],
+		}
+		switch typ {
+		case game.CardAttack, game.CardMagicArrow, game.CardRest, game.CardRetreat:
+			card.Count = -1
+		}
+		deck[typ] = card
+	}
+	return deck
+}
+
+func (r *duelRunner) Run() (out []simstep.Action) {
+	defer recoverPanic(&out)
+
+	for i := range r.sides {
+		for _, typ := range sortedCardTypes(gamedata.DuelCards) {
+			r.out = append(r.out, simstep.ChangeDuelCardCount{
+				Side:  i,
+				Name:  typ.String(),
+				Delta: gamedata.DuelCards[typ],
+			})
+		}
+	}
+
+	for ; r.turn <= maxDuelTurns; r.turn++ {
+		r.emitLogf("--- Turn %d ---", r.turn)
+		var winner int
+		var stop bool
+		if r.config.Simultaneous {
+			winner, stop = r.runSimultaneousTurn()
+		} else {
+			winner, stop = r.runAlternatingTurn()
+		}
+		r.out = append(r.out, simstep.Wait{})
+		if stop {
+			r.finish(winner)
+			return r.out
+		}
+	}
+
+	r.emitRedLogf("Duel lasted for too long!")
+	r.finish(-1)
+	return r.out
+}
+
+func (r *duelRunner) finish(winner int) {
+	r.out = append(r.out, simstep.DuelResult{Winner: winner})
+	if winner == -1 {
+		r.emitLogf("The duel ends in a draw")
+		return
+	}
+	r.emitGreenLogf("%s wins the duel!", r.sides[winner].name)
+}
+
+func (r *duelRunner) runAlternatingTurn() (winner int, stop bool) {
+	for i := range r.sides {
+		self := &r.sides[i]
+		// Parry only lasts until the next own move.
+		self.parry = false
+		if r.skipStunned(i) {
+			continue
+		}
+		cardType, played := r.playCard(i, self.tactic.ChooseCard(r.stateFor(i)))
+		if played {
+			r.resolveCard(i, cardType)
+		}
+		if winner, stop := r.checkResult(); stop {
+			return winner, true
+		}
+	}
+	return 0, false
+}
+
+func (r *duelRunner) runSimultaneousTurn() (winner int, stop bool) {
+	var cards [2]game.CardType
+	var played [2]bool
+
+	var states [2]game.State
+	for i := range r.sides {
+		states[i] = r.stateFor(i)
+	}
+	for i := range r.sides {
+		if r.skipStunned(i) {
+			continue
+		}
+		cards[i], played[i] = r.playCard(i, r.sides[i].tactic.ChooseCard(states[i]))
+	}
+
+	// Parries are resolved before any other card.
+	for i := range r.sides {
+		r.sides[i].parry = played[i] && cards[i] == game.CardParry
+	}
+	for i := range r.sides {
+		if played[i] {
+			r.resolveCard(i, cards[i])
+		}
+	}
+	for i := range r.sides {
+		r.sides[i].parry = false
+	}
+
+	return r.checkResult()
+}
+
+func (r *duelRunner) skipStunned(i int) bool {
+	self := &r.sides[i]
+	if self.stun == 0 {
+		return false
+	}
+	self.stun--
+	r.emitLogf("%s is stunned and skips the turn", self.name)
+	return true
+}
+
+func (r *duelRunner) checkResult() (winner int, stop bool) {
+	for i := range r.sides {
+		if r.sides[i].badMoves >= r.config.IllegalMoves.maxBadMoves() {
+			r.emitRedLogf("%s made too many illegal moves!", r.sides[i].name)
+			return 1 - i, true
+		}
+	}
+
+	firstDead := r.sides[0].avatar.HP <= 0
+	secondDead := r.sides[1].avatar.HP <= 0
+	switch {
+	case firstDead && secondDead:
+		return -1, true
+	case firstDead:
+		return 1, true
+	case secondDead:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+func (r *duelRunner) stateFor(i int) game.State {
+	return game.State{
+		Turn:      r.turn,
+		Round:     1,
+		RoundTurn: r.turn,
+		Avatar:    r.sides[i].avatar,
+		Opponent:  r.sides[1-i].avatar,
+		NextCreep: game.CreepNone,
+		Deck:      cloneDeck(r.sides[i].deck),
+	}
+}
+
+// validateMove checks the duelist card move without changing the duel state.
+func (r *duelRunner) validateMove(i int, cardType game.CardType) moveRejection {
+	self := &r.sides[i]
+	if reason := validateCardMove(self.deck, &self.avatar, cardType); reason != moveOK {
+		return reason
+	}
+	if !isDuelCard(cardType) {
+		return moveUnsupported
+	}
+	return moveOK
+}
+
+// rejectMove reports the duelist illegal move.
+// It returns a card to play instead, if the policy permits it.
+func (r *duelRunner) rejectMove(i int, cardType game.CardType, reason moveRejection) (game.CardType, bool) {
+	self := &r.sides[i]
+	r.recordIllegalMove(cardType, reason)
+	switch reason {
+	case moveUnknownCard:
+		r.emitRedLogf("%s tried to use unknown card %s", self.name, cardType.String())
+	case moveUnavailable:
+		r.emitRedLogf("%s tried to use unavailable card %s", self.name, cardType.String())
+	case moveNoMana:
+		r.emitRedLogf("%s has not enough mana to use %s", self.name, cardType.String())
+	case moveUnsupported:
+		r.emitRedLogf("%s can't be used in duels", cardType.String())
+	}
+
+	valid := func(typ game.CardType) bool { return r.validateMove(i, typ) == moveOK }
+	if substitute, ok := r.substituteMove(r.config.IllegalMoves, valid); ok {
+		return substitute, true
+	}
+	self.badMoves++
+	return cardType, false
+}
+
+// playCard validates the move and pays the card costs.
+// It returns the played card, which can differ from cardType
+// for the substituted illegal moves, and false if no card is played.
+func (r *duelRunner) playCard(i int, cardType game.CardType) (game.CardType, bool) {
+	if reason := r.validateMove(i, cardType); reason != moveOK {
+		substitute, ok := r.rejectMove(i, cardType, reason)
+		if !ok {
+			return cardType, false
+		}
+		cardType = substitute
+	}
+
+	self := &r.sides[i]
+	card := self.deck[cardType]
+
+	if card.Count != -1 {
+		r.out = append(r.out, simstep.ChangeDuelCardCount{
+			Side:  i,
+			Name:  cardType.String(),
+			Delta: -1,
+		})
+		changeDeckCardCount(self.deck, cardType, -1)
+	}
+	if card.MP != 0 {
+		self.avatar.MP -= card.MP
+		r.out = append(r.out, simstep.UpdateDuelMP{Side: i, Delta: -card.MP})
+	}
+	return cardType, true
+}
+
+// isDuelCard reports whether all card effects are supported in duels.
+func isDuelCard(typ game.CardType) bool {
+	effects := gamedata.GetCardEffects(typ)
+	if len(effects) == 0 {
+		return false
+	}
+	for _, e := range effects {
+		if e.If.Kind != gamedata.CondAlways {
+			return false
+		}
+		switch e.Kind {
+		case gamedata.EffectDamage, gamedata.EffectHeal, gamedata.EffectParry, gamedata.EffectRetreat:
+		case gamedata.EffectStatus:
+			if e.Status != gamedata.StatusStun {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// resolveCard applies the card effects described in the gamedata.
+// Duelists are targeted by their side index: self is i and the opponent is 1-i.
+func (r *duelRunner) resolveCard(i int, cardType game.CardType) {
+	card := r.sides[i].deck[cardType]
+	for _, e := range gamedata.GetCardEffects(cardType) {
+		r.applyCardEffect(i, cardType, card.CardStats, e)
+	}
+}
+
+func (r *duelRunner) applyCardEffect(i int, cardType game.CardType, card game.CardStats, e gamedata.CardEffect) {
+	self := &r.sides[i]
+	enemy := &r.sides[1-i]
+
+	switch e.Kind {
+	case gamedata.EffectDamage:
+		damage := r.effectAmount(card, e)
+		// Only melee attacks can be parried.
+		if enemy.parry && !card.IsMagic {
+			r.emitLogf("%s parries %s and reflects %d damage", enemy.name, cardType.String(), damage)
+			r.damage(i, damage)
+			return
+		}
+		r.emitLogf("%s %s deals %d damage", self.name, cardType.String(), damage)
+		r.damage(1-i, damage)
+
+	case gamedata.EffectStatus:
+		// Stun is the only status supported in duels, see isDuelCard.
+		enemy.stun = r.effectAmount(card, e)
+		r.emitLogf("%s stuns %s for %d turns", self.name, enemy.name, enemy.stun)
+
+	case gamedata.EffectHeal:
+		healed := calculateHealed(r.effectAmount(card, e), self.avatar.HP, self.avatar.MaxHP)
+		self.avatar.HP += healed
+		r.out = append(r.out, simstep.UpdateDuelHP{Side: i, Delta: healed})
+		r.emitGreenLogf("%s got %d HP from %s", self.name, healed, cardType.String())
+
+	case gamedata.EffectParry:
+		self.parry = true
+		r.emitLogf("%s is ready to parry", self.name)
+
+	case gamedata.EffectRetreat:
+		self.avatar.HP = 0
+		r.emitRedLogf("%s gives up", self.name)
+	}
+}
+
+func (r *duelRunner) damage(i, damage int) {
+	r.sides[i].avatar.HP -= damage
+	r.out = append(r.out, simstep.UpdateDuelHP{Side: i, Delta: -damage})
+}
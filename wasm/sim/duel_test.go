@@ -0,0 +1,117 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestRunDuel(t *testing.T) {
+	attacker := &Tactic{
+		ChooseCard: func(s game.State) game.CardType {
+			if s.Opponent.HP <= 0 {
+				t.Fatalf("turn %d: opponent is already dead", s.Turn)
+			}
+			return game.CardAttack
+		},
+	}
+	quitter := &Tactic{
+		ChooseCard: func(s game.State) game.CardType {
+			return game.CardRetreat
+		},
+	}
+
+	for _, simultaneous := range []bool{false, true} {
+		for seed := int64(0); seed < 10; seed++ {
+			config := &DuelConfig{
+				AvatarHP:     40,
+				AvatarMP:     20,
+				Seed:         seed,
+				Simultaneous: simultaneous,
+			}
+
+			out := RunDuel(config, attacker, attacker)
+			if !reflect.DeepEqual(out, RunDuel(config, attacker, attacker)) {
+				t.Fatalf("seed=%d simultaneous=%v: non-deterministic duel", seed, simultaneous)
+			}
+			if _, ok := out[len(out)-2].(simstep.DuelResult); !ok {
+				t.Fatalf("seed=%d simultaneous=%v: duel ended without a result", seed, simultaneous)
+			}
+
+			out = RunDuel(config, quitter, attacker)
+			var result *simstep.DuelResult
+			for _, a := range out {
+				if a, ok := a.(simstep.DuelResult); ok {
+					result = &a
+				}
+			}
+			want := 1
+			if result == nil || result.Winner != want {
+				t.Fatalf("seed=%d simultaneous=%v: result is %v, want winner %d", seed, simultaneous, result, want)
+			}
+		}
+	}
+}
+
+func TestDuelCards(t *testing.T) {
+	for typ := range gamedata.DuelCards {
+		if !isDuelCard(typ) {
+			t.Errorf("%s is a duel card, but its effects are not supported", typ)
+		}
+	}
+	for _, typ := range []game.CardType{game.CardAttack, game.CardMagicArrow, game.CardRest, game.CardRetreat} {
+		if !isDuelCard(typ) {
+			t.Errorf("unlimited %s card effects are not supported", typ)
+		}
+	}
+
+	// Unsupported cards are rejected before their costs are paid.
+	r := newDuelRunner(&DuelConfig{AvatarHP: 40, AvatarMP: 20}, &Tactic{}, &Tactic{})
+	changeDeckCardCount(r.sides[0].deck, game.CardChainLightning, 1)
+	if _, ok := r.playCard(0, game.CardChainLightning); ok {
+		t.Fatalf("unsupported card is played")
+	}
+	self := &r.sides[0]
+	if self.deck[game.CardChainLightning].Count != 1 || self.avatar.MP != 20 || self.badMoves != 1 {
+		t.Errorf("rejected card: have %d cards, %d MP and %d bad moves",
+			self.deck[game.CardChainLightning].Count, self.avatar.MP, self.badMoves)
+	}
+}
+
+func TestDuelIllegalMoves(t *testing.T) {
+	cheater := &Tactic{
+		ChooseCard: func(s game.State) game.CardType { return game.CardType(100) },
+	}
+	attacker := &Tactic{
+		ChooseCard: func(s game.State) game.CardType { return game.CardAttack },
+	}
+
+	tests := []struct {
+		policy   IllegalMovePolicy
+		badMoves int
+		maxTurns int
+		attacks  bool
+	}{
+		{IllegalMoveSkip, 10, 10, false},
+		{IllegalMoveStrict, 1, 1, false},
+		{IllegalMoveLenient, 0, maxDuelTurns, true},
+	}
+	for _, test := range tests {
+		config := &DuelConfig{AvatarHP: 40, AvatarMP: 20, Seed: 1, IllegalMoves: test.policy}
+		r := newDuelRunner(config, cheater, attacker)
+		r.Run()
+		if r.sides[0].badMoves != test.badMoves {
+			t.Errorf("policy %d: have %d bad moves, want %d", test.policy, r.sides[0].badMoves, test.badMoves)
+		}
+		if r.turn > test.maxTurns {
+			t.Errorf("policy %d: duel lasted for %d turns", test.policy, r.turn)
+		}
+		// Lenient policy substitutes the illegal moves with attacks.
+		if attacks := r.sides[1].avatar.HP < 40; attacks != test.attacks {
+			t.Errorf("policy %d: have attacks=%v", test.policy, attacks)
+		}
+	}
+}
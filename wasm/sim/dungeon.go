@@ -0,0 +1,126 @@
+package sim
+
+import (
+	"sort"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+// dungeonMap is a map mode layered graph.
+// Every layer corresponds to a single round.
+type dungeonMap struct {
+	layers [][]mapNode
+
+	// pos is a current node index inside the current round layer.
+	pos int
+
+	// next is a selected node index inside the next round layer.
+	next int
+}
+
+type mapNode struct {
+	game.PathOption
+
+	// creep is a creep that is encountered at this node.
+	// It's CreepNone for shops and rest sites.
+	creep game.CreepType
+
+	// next contains reachable node indexes from the next layer.
+	next []int
+}
+
+func newDungeonMap(rng roller, rounds int) *dungeonMap {
+	if rounds < 1 {
+		// The map always has at least the boss layer.
+		rounds = 1
+	}
+	m := &dungeonMap{layers: make([][]mapNode, rounds)}
+
+	for i := range m.layers {
+		round := i + 1
+		switch round {
+		case rounds:
+			// Dragon is always encountered at the last round.
+			m.layers[i] = []mapNode{{
+				PathOption: game.PathOption{Kind: game.PathBoss},
+				creep:      game.CreepDragon,
+			}}
+		case 1:
+			// Cheepy is always encountered at the first round.
+			m.layers[i] = []mapNode{{
+				PathOption: game.PathOption{Kind: game.PathCreep, Tier: 1},
+				creep:      game.CreepCheepy,
+			}}
+		default:
+			layer := make([]mapNode, 2+rng.Intn(2))
+			for j := range layer {
+				layer[j] = newMapNode(rng, round)
+			}
+			m.layers[i] = layer
+		}
+	}
+
+	for i := 0; i < len(m.layers)-1; i++ {
+		current, next := m.layers[i], m.layers[i+1]
+		// Every node should have at least one outgoing and one incoming edge.
+		for j := range current {
+			current[j].link(j * len(next) / len(current))
+		}
+		for k := range next {
+			current[k*len(current)/len(next)].link(k)
+		}
+		// Add some random shortcuts to make the map more branchy.
+		for j := range current {
+			if rng.Intn(3) == 0 {
+				current[j].link(rng.Intn(len(next)))
+			}
+		}
+		for j := range current {
+			sort.Ints(current[j].next)
+		}
+	}
+
+	return m
+}
+
+func newMapNode(rng roller, round int) mapNode {
+	roll := rng.Intn(100)
+	switch {
+	case roll >= 85: // 15%
+		return mapNode{PathOption: game.PathOption{Kind: game.PathShop}}
+	case roll >= 70: // 15%
+		return mapNode{PathOption: game.PathOption{Kind: game.PathRest}}
+	}
+
+	// First 5 rounds can't have high-tier enemies.
+	maxTier := 3
+	if round <= 5 {
+		maxTier = 2
+	}
+	tier := 1 + rng.Intn(maxTier)
+	creeps := gamedata.CreepTiers[tier]
+	return mapNode{
+		PathOption: game.PathOption{Kind: game.PathCreep, Tier: tier},
+		creep:      creeps[rng.Intn(len(creeps))],
+	}
+}
+
+func (n *mapNode) link(index int) {
+	for _, x := range n.next {
+		if x == index {
+			return
+		}
+	}
+	n.next = append(n.next, index)
+}
+
+// current returns a node that is visited during the specified round.
+func (m *dungeonMap) current(round int) *mapNode {
+	return &m.layers[round-1][m.pos]
+}
+
+// node returns a node from the specified round layer.
+func (m *dungeonMap) node(round, index int) *mapNode {
+	return &m.layers[round-1][index]
+}
@@ -0,0 +1,71 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestRunMapMode(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		config := &Config{
+			AvatarHP: 40,
+			AvatarMP: 20,
+			Rounds:   10,
+			Seed:     seed,
+			MapMode:  true,
+		}
+		pathChoices := 0
+		tactic := &Tactic{
+			ChooseCard: func(game.State) game.CardType { return game.CardRetreat },
+			ChoosePath: func(s game.State, paths []game.PathOption) int {
+				pathChoices++
+				if len(paths) == 0 {
+					t.Fatalf("seed=%d round=%d: empty paths list", seed, s.Round)
+				}
+				if s.Round == config.Rounds-1 && paths[0].Kind != game.PathBoss {
+					t.Fatalf("seed=%d: the last path is %s, not a boss", seed, paths[0].Kind)
+				}
+				if s.NextCreep != game.CreepNone {
+					t.Fatalf("seed=%d round=%d: stale next creep %s", seed, s.Round, s.NextCreep)
+				}
+				return len(paths) - 1
+			},
+		}
+
+		firstResult := RunTactic(config, tactic)
+		secondResult := RunTactic(config, tactic)
+		if !reflect.DeepEqual(firstResult, secondResult) {
+			t.Errorf("seed=%d different results", seed)
+		}
+		if pathChoices != 2*(config.Rounds-1) {
+			t.Errorf("seed=%d: ChoosePath is called %d times", seed, pathChoices)
+		}
+		if _, ok := firstResult[len(firstResult)-1].(simstep.GreenLog); !ok {
+			t.Errorf("seed=%d: game is not completed", seed)
+		}
+	}
+
+	// Reward cards can run out, see campaignRun.cards.
+	r := newTestRunner(t, &Config{Seed: 1, MapMode: true})
+	r.peekableCards = nil
+	r.state.Score = 20
+	r.visitShop()
+	if r.state.Score != 20 {
+		t.Errorf("paid %d score points for nothing", 20-r.state.Score)
+	}
+
+	for _, rounds := range []int{0, -1} {
+		config := &Config{AvatarHP: 40, AvatarMP: 20, Rounds: rounds, MapMode: true}
+		result := RunTactic(config, &Tactic{
+			ChooseCard: func(game.State) game.CardType { return game.CardRetreat },
+		})
+		for _, a := range result {
+			if a, ok := a.(simstep.RedLog); ok {
+				t.Errorf("rounds=%d: %s", rounds, a.Message)
+			}
+		}
+	}
+}
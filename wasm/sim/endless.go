@@ -0,0 +1,105 @@
+package sim
+
+import (
+	"fmt"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// endlessRoundsLimit guarantees that the endless mode game terminates.
+const endlessRoundsLimit = 1000
+
+// maxScalingPercent limits the scaling curve coefficients,
+// so the scaled stats can't overflow even at the endlessRoundsLimit.
+const maxScalingPercent = 1000
+
+// ScalingCurve describes how creep stats grow with the round number.
+//
+// For the round N, stats are increased by Linear*(N-1) + Quadratic*(N-1)^2 percents.
+// Both coefficients should be in [0, 1000] range.
+type ScalingCurve struct {
+	Linear    int
+	Quadratic int
+}
+
+func (c ScalingCurve) validate() error {
+	for _, percent := range []int{c.Linear, c.Quadratic} {
+		if percent < 0 || percent > maxScalingPercent {
+			return fmt.Errorf("coefficient %d is out of [0, %d] range", percent, maxScalingPercent)
+		}
+	}
+	return nil
+}
+
+func (c ScalingCurve) apply(value, round int) int {
+	n := round - 1
+	return value * (100 + c.Linear*n + c.Quadratic*n*n) / 100
+}
+
+// scale applies the curve to the creep stats.
+// Scaled HP and damage are never below 1.
+func (c ScalingCurve) scale(stats game.CreepStats, round int) game.CreepStats {
+	stats.MaxHP = atLeast(1, c.apply(stats.MaxHP, round))
+	low := atLeast(1, c.apply(stats.Damage.Low(), round))
+	high := atLeast(low, c.apply(stats.Damage.High(), round))
+	stats.Damage = game.IntRange{low, high}
+	stats.ScoreReward = atLeast(0, c.apply(stats.ScoreReward, round))
+	return stats
+}
+
+func atLeast(min, value int) int {
+	if value < min {
+		return min
+	}
+	return value
+}
+
+func (r *runner) peekEndlessCreep(round int) game.CreepType {
+	if r.config.BossEvery > 0 && round%r.config.BossEvery == 0 {
+		return game.CreepDragon
+	}
+	switch round {
+	case 1:
+		return game.CreepCheepy
+	case 2:
+		return game.CreepImp
+	default:
+		return r.rollCreep(round)
+	}
+}
+
+// finishEndless reports the endless run result and updates the high-water mark.
+// It's called for every run end, not only for the avatar defeat.
+func (r *runner) finishEndless() {
+	best := r.config.BestScore
+	r.emitGreenLogf("Reached round %d with %d score points", r.state.Round, r.state.Score)
+	if r.state.Score > best {
+		best = r.state.Score
+		r.emitGreenLogf("New best score!")
+	}
+	r.out = append(r.out, simstep.EndlessResult{
+		Round:     r.state.Round,
+		Score:     r.state.Score,
+		BestScore: best,
+	})
+}
+
+// creepStats returns creep stats adjusted for the specified round.
+func (r *runner) creepStats(typ game.CreepType, round int) game.CreepStats {
+	stats := gamedata.GetCreepStats(typ)
+	if r.config.Endless {
+		stats = r.config.Scaling.scale(stats, round)
+	}
+	if r.config.RiskyRetreat {
+		stats.Traits = appendTraits(stats.Traits, gamedata.GetRiskyRetreatTraits(typ)...)
+	}
+	return stats
+}
+
+func (r *runner) newCreep(typ game.CreepType, round int) game.Creep {
+	creep := newCreep(typ, r.creepStats(typ, round))
+	r.modifyCreep(&creep)
+	return creep
+}
@@ -0,0 +1,135 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestScalingCurve(t *testing.T) {
+	tests := []struct {
+		curve ScalingCurve
+		value int
+		round int
+		want  int
+	}{
+		{ScalingCurve{}, 10, 1, 10},
+		{ScalingCurve{}, 10, 50, 10},
+		{ScalingCurve{Linear: 10}, 10, 1, 10},
+		{ScalingCurve{Linear: 10}, 10, 2, 11},
+		{ScalingCurve{Linear: 10}, 10, 11, 20},
+		{ScalingCurve{Quadratic: 1}, 10, 11, 20},
+		{ScalingCurve{Linear: 5, Quadratic: 1}, 20, 5, 27},
+	}
+
+	for _, test := range tests {
+		have := test.curve.apply(test.value, test.round)
+		if have != test.want {
+			t.Errorf("%+v value=%d round=%d:\nhave: %d\nwant: %d",
+				test.curve, test.value, test.round, have, test.want)
+		}
+	}
+
+	// Scaled stats are clamped, so the damage range is always valid.
+	stats := game.CreepStats{MaxHP: 4, Damage: game.IntRange{1, 4}, ScoreReward: 3}
+	scaled := ScalingCurve{Linear: -50}.scale(stats, 10)
+	if scaled.MaxHP != 1 || scaled.Damage != (game.IntRange{1, 1}) || scaled.ScoreReward != 0 {
+		t.Errorf("negative scaling: %+v", scaled)
+	}
+
+	invalid := []ScalingCurve{
+		{Linear: -1},
+		{Quadratic: -1},
+		{Linear: maxScalingPercent + 1},
+		{Quadratic: maxScalingPercent + 1},
+	}
+	for _, curve := range invalid {
+		config := &Config{AvatarHP: 40, AvatarMP: 20, Endless: true, Scaling: curve}
+		result := Run(config, func(game.State) game.CardType { return game.CardAttack })
+		if len(result) != 1 || !strings.HasPrefix(result[0].(simstep.RedLog).Message, "Invalid config") {
+			t.Errorf("%+v: invalid curve is accepted: %v", curve, result)
+		}
+	}
+}
+
+func TestRunEndless(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		config := &Config{
+			AvatarHP:  40,
+			AvatarMP:  20,
+			Seed:      seed,
+			Endless:   true,
+			BossEvery: 4,
+			Scaling:   ScalingCurve{Linear: 20},
+		}
+		result := Run(config, func(game.State) game.CardType { return game.CardAttack })
+
+		round := 1
+		defeated := false
+		for _, a := range result {
+			switch a := a.(type) {
+			case simstep.NextRound:
+				round++
+			case simstep.SetCreep:
+				if (a.Name == "Dragon") != (round%config.BossEvery == 0) {
+					t.Fatalf("seed=%d: unexpected %s at round %d", seed, a.Name, round)
+				}
+			case simstep.Victory:
+				t.Fatalf("seed=%d: victory in the endless mode", seed)
+			case simstep.Defeat:
+				defeated = true
+			}
+		}
+		if !defeated {
+			t.Errorf("seed=%d: endless game is not finished by defeat", seed)
+		}
+	}
+}
+
+func TestEndlessBestScore(t *testing.T) {
+	endlessResult := func(config *Config, chooseCard func(game.State) game.CardType) simstep.EndlessResult {
+		var results []simstep.EndlessResult
+		score := 0
+		for _, a := range Run(config, chooseCard) {
+			switch a := a.(type) {
+			case simstep.UpdateScore:
+				score += a.Delta
+			case simstep.EndlessResult:
+				results = append(results, a)
+			}
+		}
+		if len(results) != 1 {
+			t.Fatalf("have %d endless results, want 1", len(results))
+		}
+		if results[0].Score != score {
+			t.Fatalf("result score is %d, want %d", results[0].Score, score)
+		}
+		return results[0]
+	}
+	attack := func(game.State) game.CardType { return game.CardAttack }
+
+	config := &Config{AvatarHP: 40, AvatarMP: 20, Seed: 1, Endless: true, Scaling: ScalingCurve{Linear: 10}}
+	result := endlessResult(config, attack)
+	if result.Score == 0 || result.BestScore != result.Score {
+		t.Fatalf("first run: %+v", result)
+	}
+	config.BestScore = result.Score + 1
+	if result := endlessResult(config, attack); result.BestScore != config.BestScore {
+		t.Fatalf("best score is lowered: %+v", result)
+	}
+
+	// The mark is also updated when the run is not ended by the avatar defeat.
+	config.BestScore = 0
+	config.IllegalMoves = IllegalMoveStrict
+	illegal := func(s game.State) game.CardType {
+		if s.Round == 3 {
+			return game.CardHeal
+		}
+		return game.CardAttack
+	}
+	if result := endlessResult(config, illegal); result.Round != 3 || result.BestScore == 0 {
+		t.Fatalf("illegal move: %+v", result)
+	}
+}
@@ -0,0 +1,336 @@
+package sim
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// FuzzRun drives the simulation with random configs and moves.
+// The seed corpus is checked by the regular "go test" run;
+// use "go test -fuzz FuzzRun" to explore more inputs.
+func FuzzRun(f *testing.F) {
+	f.Add(int64(1), uint16(0), []byte{})
+	f.Add(int64(2), uint16(0), []byte{2})
+	f.Add(int64(3), uint16(0xffff), []byte{0, 1, 4, 5, 6, 7, 8})
+	f.Add(int64(4), uint16(1<<3|1<<10), []byte{7, 7, 3, 2})
+	f.Add(int64(5), uint16(1<<2|1<<6|1<<8), []byte{12, 13, 14, 15, 16, 17, 18})
+	f.Add(int64(6), uint16(1<<5|1<<7|1<<9|1<<11), []byte{8, 0, 6, 4, 12, 0})
+	f.Add(int64(7), uint16(1<<3), []byte{0, 4, 0, 0, 5})
+	f.Add(int64(8), uint16(1<<3|1<<6), []byte{1, 6, 3, 2, 1})
+	f.Fuzz(func(t *testing.T, seed int64, flags uint16, moves []byte) {
+		checkSimInvariants(t, fuzzConfig(seed, flags), moves)
+	})
+}
+
+func TestSimInvariants(t *testing.T) {
+	for seed := int64(0); seed < 50; seed++ {
+		flags := uint16(seed * 1237)
+		moves := []byte{byte(seed), byte(seed * 3), byte(seed * 7), byte(seed * 11)}
+		checkSimInvariants(t, fuzzConfig(seed, flags), moves)
+	}
+}
+
+// FuzzRunDuel checks that duels always end with a single result.
+func FuzzRunDuel(f *testing.F) {
+	f.Add(int64(1), false, uint8(0), []byte{}, []byte{})
+	f.Add(int64(2), true, uint8(0), []byte{0, 1, 2}, []byte{7})
+	f.Add(int64(3), false, uint8(1), []byte{4, 5, 6}, []byte{12, 13})
+	f.Add(int64(4), true, uint8(2), []byte{8, 8, 3}, []byte{20, 0, 1})
+	f.Fuzz(func(t *testing.T, seed int64, simultaneous bool, policy uint8, moves1, moves2 []byte) {
+		config := &DuelConfig{
+			AvatarHP:     40,
+			AvatarMP:     20,
+			Seed:         seed,
+			Simultaneous: simultaneous,
+			IllegalMoves: IllegalMovePolicy(policy % 3),
+		}
+		first, second := fuzzTactic(t, moves1, false), fuzzTactic(t, moves2, false)
+		actions := RunDuel(config, first, second)
+		checkNoPanic(t, actions)
+		results := 0
+		for _, a := range actions {
+			if a, ok := a.(simstep.DuelResult); ok {
+				results++
+				if a.Winner < -1 || a.Winner > 1 {
+					t.Fatalf("bad duel winner %d", a.Winner)
+				}
+			}
+		}
+		if results != 1 {
+			t.Fatalf("have %d duel results, want 1", results)
+		}
+	})
+}
+
+// FuzzRunDungeonMaster checks the game invariants with arbitrary dungeon master choices.
+func FuzzRunDungeonMaster(f *testing.F) {
+	f.Add(int64(1), uint16(0), []byte{}, []byte{})
+	f.Add(int64(2), uint16(1<<0|1<<1), []byte{0, 1, 2}, []byte{2, 3, 4, 5})
+	f.Add(int64(3), uint16(1<<3|1<<8), []byte{7, 7, 3}, []byte{11, 0, 200, 6})
+	f.Fuzz(func(t *testing.T, seed int64, flags uint16, moves, masterMoves []byte) {
+		config := fuzzConfig(seed, flags)
+		config.MapMode = false
+		turn := 0
+		masterMove := func() int {
+			if len(masterMoves) == 0 {
+				return 0
+			}
+			turn++
+			return int(masterMoves[turn%len(masterMoves)])
+		}
+		master := &DungeonMaster{
+			ChooseCreep: func(s game.State, round, budget int) game.CreepType {
+				return game.CreepType(masterMove() % int(game.CreepGhost+2))
+			},
+			ChooseIntent: func(s game.State, options []game.CreepIntent) game.CreepIntent {
+				return game.CreepIntent(masterMove() % int(game.IntentCast+2))
+			},
+		}
+		actions := RunDungeonMaster(&config, fuzzTactic(t, moves, false), master)
+		checkNoPanic(t, actions)
+	})
+}
+
+// FuzzRunPuzzle checks that any valid scenario can be played.
+func FuzzRunPuzzle(f *testing.F) {
+	files, err := filepath.Glob(filepath.Join("..", "..", "www", "puzzles", "*.json"))
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, filename := range files {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data, []byte{0, 1, 2})
+	}
+	f.Add([]byte(`{"creeps": ["Lion"], "rolls": [-5, 100]}`), []byte{})
+	f.Add([]byte(`{"creeps": ["Unknown"]}`), []byte{})
+	f.Add([]byte(`{"creeps": [], "cards": {"Heal": -1}}`), []byte{})
+	f.Fuzz(func(t *testing.T, data, moves []byte) {
+		scenario, err := ParseScenario(data)
+		if err != nil {
+			return
+		}
+		config := &Config{AvatarHP: 40, AvatarMP: 20}
+		actions := RunPuzzle(config, scenario, fuzzTactic(t, moves, false))
+		checkNoPanic(t, actions)
+		results := 0
+		for _, a := range actions {
+			if _, ok := a.(simstep.PuzzleResult); ok {
+				results++
+			}
+		}
+		if results != 1 {
+			t.Fatalf("have %d puzzle results, want 1", results)
+		}
+	})
+}
+
+// FuzzRunCampaign checks that any accepted progress can be played and saved.
+func FuzzRunCampaign(f *testing.F) {
+	f.Add([]byte(``), int64(1), []byte{})
+	f.Add([]byte(`{"chapter": 1, "cards": ["Heal", "Unknown"], "creeps": ["Mummy"]}`), int64(2), []byte{0, 1})
+	f.Add([]byte(`{"chapter": 2, "bestScores": [10]}`), int64(3), []byte{2, 3, 4})
+	f.Add([]byte(`{"chapter": 3}`), int64(4), []byte{})
+	f.Add([]byte(`{"chapter": -1}`), int64(5), []byte{})
+	f.Fuzz(func(t *testing.T, data []byte, seed int64, moves []byte) {
+		progress, err := ParseCampaignProgress(data)
+		if err != nil {
+			return
+		}
+		config := &Config{AvatarHP: 40, AvatarMP: 20, Seed: seed}
+		actions := RunCampaign(config, progress, fuzzTactic(t, moves, false))
+		checkNoPanic(t, actions)
+		for _, a := range actions {
+			if a, ok := a.(simstep.SaveProgress); ok {
+				if _, err := ParseCampaignProgress([]byte(a.Data)); err != nil {
+					t.Fatalf("saved progress can't be loaded: %v", err)
+				}
+			}
+		}
+	})
+}
+
+// fuzzConfig decodes the flags into the game settings.
+// The endless mode is never enabled as it can last forever.
+func fuzzConfig(seed int64, flags uint16) Config {
+	config := Config{
+		AvatarHP: 40,
+		AvatarMP: 20,
+		Rounds:   10,
+		Seed:     seed,
+
+		Bosses:         flags&(1<<0) != 0,
+		Intents:        flags&(1<<1) != 0,
+		MapMode:        flags&(1<<2) != 0,
+		Draft:          flags&(1<<3) != 0,
+		Leveling:       flags&(1<<4) != 0,
+		CombatRolls:    flags&(1<<5) != 0,
+		ExpansionCards: flags&(1<<6) != 0,
+		Combos:         flags&(1<<7) != 0,
+		Potions:        flags&(1<<8) != 0,
+		RiskyRetreat:   flags&(1<<9) != 0,
+		Class:          game.AvatarClass((flags >> 10) % 4),
+	}
+	if flags&(1<<12) != 0 {
+		config.Modifiers = []Modifier{ModifierGlassCannon, ModifierDoubleLoot}
+	}
+	return config
+}
+
+// checkSimInvariants runs the game with the same moves twice:
+// the second tactic corrupts every state it receives, but it
+// should have no effect on the game results.
+func checkSimInvariants(t *testing.T, config Config, moves []byte) {
+	var results [2][]simstep.Action
+	for i, mutate := range []bool{false, true} {
+		config := config
+		r := newRunner(&config, fuzzTactic(t, moves, mutate))
+		actions := r.Run()
+		results[i] = actions
+
+		score := 0
+		victory, defeat := false, false
+		for _, a := range actions {
+			switch a := a.(type) {
+			case simstep.UpdateScore:
+				score += a.Delta
+			case simstep.Victory:
+				victory = true
+			case simstep.Defeat:
+				defeat = true
+			}
+		}
+		if score != r.state.Score {
+			t.Fatalf("score is %d, but UpdateScore deltas sum is %d", r.state.Score, score)
+		}
+		if victory && defeat {
+			t.Fatalf("both victory and defeat are emitted")
+		}
+		checkStateInvariants(t, *r.state)
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Fatalf("mutating the tactic state changed the game results")
+	}
+}
+
+func checkStateInvariants(t *testing.T, s game.State) {
+	if s.Avatar.HP > s.Avatar.MaxHP {
+		t.Fatalf("turn %d: avatar HP %d exceeds MaxHP %d", s.Turn, s.Avatar.HP, s.Avatar.MaxHP)
+	}
+	if s.Avatar.MP < 0 {
+		t.Fatalf("turn %d: avatar MP is negative: %d", s.Turn, s.Avatar.MP)
+	}
+	// The draft budget and the rewards can't give that much.
+	const maxStat = 10000
+	if s.Avatar.MaxHP > maxStat || s.Avatar.MaxMP > maxStat {
+		t.Fatalf("turn %d: avatar MaxHP=%d MaxMP=%d", s.Turn, s.Avatar.MaxHP, s.Avatar.MaxMP)
+	}
+	for typ, card := range s.Deck {
+		if card.Count < -1 || card.Count > maxStat {
+			t.Fatalf("turn %d: %s card count is %d", s.Turn, typ, card.Count)
+		}
+	}
+}
+
+// fuzzTactic returns a tactic that cycles through the encoded moves.
+func fuzzTactic(t *testing.T, moves []byte, mutate bool) *Tactic {
+	const maxTurns = 10000
+	turn := 0
+	move := func() int {
+		if len(moves) == 0 {
+			return 0
+		}
+		return int(moves[turn%len(moves)])
+	}
+	return &Tactic{
+		ChooseCard: func(s game.State) game.CardType {
+			checkStateInvariants(t, s)
+			turn++
+			if turn > maxTurns {
+				t.Fatalf("the game is not over after %d turns", maxTurns)
+			}
+			if mutate {
+				mutateState(&s)
+			}
+			return game.CardType(move() % int(game.CardScrollOfInsight+1))
+		},
+		ChoosePath: func(s game.State, options []game.PathOption) int {
+			if mutate {
+				mutateState(&s)
+			}
+			return move() % len(options)
+		},
+		BuildDeck: func(offer game.DraftOffer) game.Loadout {
+			// Huge amounts check that the loadout cost can't overflow.
+			amounts := []int{0, 1, 2, 5, -1, math.MaxInt64/2 + 1, math.MaxInt64}
+			i := 0
+			next := func() int {
+				i++
+				if len(moves) == 0 {
+					return 0
+				}
+				return int(moves[i%len(moves)])
+			}
+			loadout := game.Loadout{
+				Cards:   map[game.CardType]int{game.CardType(next() % int(game.CardScrollOfInsight+1)): amounts[next()%len(amounts)]},
+				ExtraHP: amounts[next()%len(amounts)],
+				ExtraMP: amounts[next()%len(amounts)],
+			}
+			if next()%2 == 1 {
+				loadout.Drop = []game.CardType{game.CardMagicArrow}
+			}
+			if mutate {
+				for typ := range offer.CardPrices {
+					offer.CardPrices[typ] = 0
+				}
+			}
+			return loadout
+		},
+		UsePotion: func(s game.State) game.PotionType {
+			potion := game.PotionNone
+			if move()%2 == 1 {
+				potion = s.Potions[0].Type
+			}
+			if mutate {
+				mutateState(&s)
+			}
+			return potion
+		},
+	}
+}
+
+// mutateState corrupts everything the tactic can reach through the state.
+func mutateState(s *game.State) {
+	s.Avatar.HP = 1000
+	s.Avatar.MP = -1
+	s.Score = -1
+	s.Creep.HP = 0
+	for typ, card := range s.Deck {
+		card.Count = 99
+		card.MP = 0
+		s.Deck[typ] = card
+	}
+	for i := range s.Forecast {
+		s.Forecast[i] = game.CreepDragon
+	}
+	for i := range s.LastCards {
+		s.LastCards[i] = game.CardParry
+	}
+	for i := range s.Potions {
+		s.Potions[i].Type = game.PotionGreaterHealing
+	}
+	for i := range s.Creep.Traits {
+		s.Creep.Traits[i] = game.TraitMagicImmunity
+	}
+	for e := range s.Creep.Resistances {
+		s.Creep.Resistances[e] = 100
+	}
+}
@@ -0,0 +1,80 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func (r *runner) gainXP(xp int) {
+	avatar := &r.state.Avatar
+
+	avatar.XP += xp
+	r.emitGreenLogf("Got %d XP", xp)
+	for avatar.XP >= gamedata.XPToLevelUp(avatar.Level) {
+		avatar.XP -= gamedata.XPToLevelUp(avatar.Level)
+		avatar.Level++
+		r.out = append(r.out, simstep.LevelUp{Level: avatar.Level})
+		r.emitGreenLogf("Reached level %d!", avatar.Level)
+		r.applyPerk(r.choosePerk())
+	}
+}
+
+// choosePerk asks the tactic to select one of the randomly offered perks.
+func (r *runner) choosePerk() game.Perk {
+	perks := make([]game.Perk, len(gamedata.Perks))
+	copy(perks, gamedata.Perks)
+	r.rand.Shuffle(len(perks), func(i, j int) {
+		perks[i], perks[j] = perks[j], perks[i]
+	})
+	perks = perks[:gamedata.PerkChoices]
+
+	if r.tactic.ChoosePerk == nil {
+		return perks[0]
+	}
+	offered := make([]game.Perk, len(perks))
+	copy(offered, perks)
+	selected := r.tactic.ChoosePerk(cloneState(r.state), offered)
+	for _, perk := range perks {
+		if perk == selected {
+			return perk
+		}
+	}
+	r.emitRedLogf("Tried to select unavailable perk %s", selected.String())
+	r.badMoves++
+	return perks[0]
+}
+
+func (r *runner) applyPerk(perk game.Perk) {
+	avatar := &r.state.Avatar
+
+	switch perk {
+	case game.PerkVitality:
+		avatar.MaxHP += gamedata.VitalityHP
+		avatar.HP += gamedata.VitalityHP
+		r.out = append(r.out, simstep.UpdateHP{Delta: gamedata.VitalityHP})
+	case game.PerkWisdom:
+		avatar.MaxMP += gamedata.WisdomMP
+		avatar.MP += gamedata.WisdomMP
+		r.out = append(r.out, simstep.UpdateMP{Delta: gamedata.WisdomMP})
+	case game.PerkStrength:
+		r.upgradeDamageCards(false)
+	case game.PerkSorcery:
+		r.upgradeDamageCards(true)
+	}
+	r.emitGreenLogf("Selected %s perk", perk.String())
+}
+
+func (r *runner) upgradeDamageCards(magic bool) {
+	for typ, card := range r.state.Deck {
+		// Only the damage dealing cards are upgraded.
+		if card.IsMagic != magic || !card.IsOffensive || !gamedata.HasEffect(typ, gamedata.EffectDamage) {
+			continue
+		}
+		card.Power = game.IntRange{
+			card.Power.Low() + gamedata.PerkPower,
+			card.Power.High() + gamedata.PerkPower,
+		}
+		r.state.Deck[typ] = card
+	}
+}
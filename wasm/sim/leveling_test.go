@@ -0,0 +1,52 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestRunLeveling(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		config := &Config{
+			AvatarHP: 100,
+			AvatarMP: 20,
+			Rounds:   10,
+			Seed:     seed,
+			Leveling: true,
+		}
+		strength := 0
+		tactic := &Tactic{
+			ChooseCard: func(s game.State) game.CardType {
+				attack := s.Deck[game.CardAttack].Power
+				if attack.Low() != 2+strength || attack.High() != 4+strength {
+					t.Fatalf("seed=%d: Attack power is %v after %d strength perks", seed, attack, strength)
+				}
+				return game.CardAttack
+			},
+			ChoosePerk: func(s game.State, perks []game.Perk) game.Perk {
+				if len(perks) != 2 {
+					t.Fatalf("seed=%d: offered %d perks", seed, len(perks))
+				}
+				for _, p := range perks {
+					if p == game.PerkStrength {
+						strength++
+						return p
+					}
+				}
+				return perks[0]
+			},
+		}
+
+		levels := 0
+		for _, a := range RunTactic(config, tactic) {
+			if _, ok := a.(simstep.LevelUp); ok {
+				levels++
+			}
+		}
+		if levels == 0 {
+			t.Errorf("seed=%d: no level-ups", seed)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// DungeonMaster is a set of user-provided functions that control the creeps.
+// The dungeon master tries to minimize the avatar score.
+type DungeonMaster struct {
+	// ChooseCreep returns a creep to be encountered at the specified round.
+	// The creep cost is paid from the remaining budget, see gamedata.CreepCosts.
+	// The Dragon is always encountered at the last round.
+	// If it's nil, creeps are selected like in the classic mode.
+	ChooseCreep func(s game.State, round, budget int) game.CreepType
+
+	// ChooseIntent returns the current creep next move intent.
+	// If it's nil, the creep AI is used.
+	ChooseIntent func(game.State, []game.CreepIntent) game.CreepIntent
+}
+
+// RunDungeonMaster runs a game where creeps are controlled by the dungeon master.
+func RunDungeonMaster(config *Config, tactic *Tactic, master *DungeonMaster) []simstep.Action {
+	runner := newRunner(config, tactic)
+	runner.master = master
+	runner.masterBudget = gamedata.DungeonMasterBudget
+	return runner.Run()
+}
+
+func (r *runner) masterCreep(round int) game.CreepType {
+	typ := r.master.ChooseCreep(cloneState(r.state), round, r.masterBudget)
+	cost, ok := gamedata.CreepCosts[typ]
+	switch {
+	case !ok:
+		r.emitRedLogf("Dungeon master can't send %s", typ.String())
+		typ, cost = game.CreepCheepy, 0
+	case cost > r.masterBudget:
+		r.emitRedLogf("Dungeon master can't afford %s", typ.String())
+		typ, cost = game.CreepCheepy, 0
+	}
+	r.masterBudget -= cost
+	return typ
+}
+
+func (r *runner) masterIntent(creep *game.Creep) game.CreepIntent {
+	options := creepIntentOptions(r, creep)
+	intent := r.master.ChooseIntent(cloneState(r.state), options)
+	for _, x := range options {
+		if x == intent {
+			return intent
+		}
+	}
+	r.emitRedLogf("%s can't use %s intent", creep.Type.String(), intent.String())
+	return options[0]
+}
+
+// creepIntentOptions returns intents that the creep can use during its next move.
+// The first option is always a valid fallback.
+func creepIntentOptions(r *runner, creep *game.Creep) []game.CreepIntent {
+	if boss, ok := gamedata.GetBossStats(creep.Type); ok && r.config.Bosses {
+		if mustDeliverCharge(creep) {
+			return []game.CreepIntent{game.IntentHeavyAttack}
+		}
+		options := []game.CreepIntent{game.IntentAttack, game.IntentCharge, game.IntentHeal}
+		if canSummon(creep, boss) {
+			options = append(options, game.IntentSummon)
+		}
+		return options
+	}
+
+	options := []game.CreepIntent{game.IntentAttack}
+	for _, w := range gamedata.GetCreepIntents(creep.Type) {
+		if w.Intent == game.IntentAttack {
+			continue
+		}
+		if w.Intent == game.IntentFlee && !canFlee(creep) {
+			continue
+		}
+		options = append(options, w.Intent)
+	}
+	return options
+}
@@ -0,0 +1,82 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestRunDungeonMaster(t *testing.T) {
+	for seed := int64(0); seed < 10; seed++ {
+		config := &Config{
+			AvatarHP: 40,
+			AvatarMP: 20,
+			Rounds:   10,
+			Seed:     seed,
+		}
+		tactic := &Tactic{
+			ChooseCard: func(s game.State) game.CardType {
+				return game.CardAttack
+			},
+		}
+
+		var creeps []game.CreepType
+		intents := 0
+		master := &DungeonMaster{
+			ChooseCreep: func(s game.State, round, budget int) game.CreepType {
+				if budget < 0 {
+					t.Fatalf("seed=%d: negative budget %d", seed, budget)
+				}
+				if round == 1 {
+					// Dragon can't be selected by the dungeon master.
+					return game.CreepDragon
+				}
+				return game.CreepMummy
+			},
+			ChooseIntent: func(s game.State, options []game.CreepIntent) game.CreepIntent {
+				if options[0] != game.IntentAttack {
+					t.Fatalf("seed=%d: %s options start with %s", seed, s.Creep.Type, options[0])
+				}
+				intents++
+				return options[len(options)-1]
+			},
+		}
+
+		out := RunDungeonMaster(config, tactic, master)
+		for _, a := range out {
+			if a, ok := a.(simstep.SetCreep); ok {
+				creeps = append(creeps, creepByName(t, a.Name))
+			}
+		}
+		if len(creeps) == 0 || creeps[0] != game.CreepCheepy {
+			t.Fatalf("seed=%d: first creeps are %v, want Cheepy fallback", seed, creeps)
+		}
+		mummies := 0
+		for _, c := range creeps {
+			if c == game.CreepMummy {
+				mummies++
+			}
+		}
+		if mummies > 4 {
+			t.Fatalf("seed=%d: %d mummies sent with a budget of 20", seed, mummies)
+		}
+		if intents == 0 {
+			t.Fatalf("seed=%d: ChooseIntent is never called", seed)
+		}
+		if !reflect.DeepEqual(out, RunDungeonMaster(config, tactic, master)) {
+			t.Fatalf("seed=%d: non-deterministic run", seed)
+		}
+	}
+}
+
+func creepByName(t *testing.T, name string) game.CreepType {
+	for typ := game.CreepNone; typ <= game.CreepGhost; typ++ {
+		if typ.String() == name {
+			return typ
+		}
+	}
+	t.Fatalf("unknown creep %q", name)
+	return game.CreepNone
+}
@@ -0,0 +1,207 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// Modifier is a run mutator that changes the game rules.
+type Modifier int
+
+// All modifiers.
+//go:generate stringer -type=Modifier -trimprefix=Modifier
+const (
+	// ModifierGlassCannon halves the avatar HP and doubles its damage.
+	ModifierGlassCannon Modifier = iota
+
+	// ModifierNoMagic removes magic cards from the game.
+	ModifierNoMagic
+
+	// ModifierCowards gives the Coward trait to all creeps.
+	ModifierCowards
+
+	// ModifierDoubleLoot doubles the cards reward.
+	ModifierDoubleLoot
+
+	// ModifierDragonEvery5 makes the Dragon appear every 5th round.
+	ModifierDragonEvery5
+)
+
+// ParseModifier returns a modifier by its name.
+func ParseModifier(name string) (Modifier, bool) {
+	for m := range modifiers {
+		if m.String() == name {
+			return m, true
+		}
+	}
+	return 0, false
+}
+
+// modifierHooks describes how the modifier affects the runner.
+// Every hook is optional; hooks of several modifiers are composed
+// in the order of Config.Modifiers.
+type modifierHooks struct {
+	// scorePercent is a final score multiplier in percents.
+	scorePercent int
+
+	// initDeck is called after the deck and the avatar are initialized.
+	initDeck func(r *runner)
+
+	// peekCreep can replace a creep that was selected for the round.
+	peekCreep func(r *runner, round int, typ game.CreepType) game.CreepType
+
+	// spawnCreep is called for every created creep.
+	spawnCreep func(creep *game.Creep)
+
+	// rule implements the modifier mechanics that are expressed via rule hooks.
+	rule rule
+}
+
+var modifiers = map[Modifier]*modifierHooks{
+	ModifierGlassCannon: {
+		scorePercent: 150,
+		initDeck: func(r *runner) {
+			avatar := &r.state.Avatar
+			delta := avatar.MaxHP/2 - avatar.MaxHP
+			avatar.MaxHP += delta
+			avatar.HP += delta
+			r.out = append(r.out, simstep.UpdateHP{Delta: delta})
+		},
+		rule: glassCannonRule{},
+	},
+
+	ModifierNoMagic: {
+		scorePercent: 130,
+		initDeck: func(r *runner) {
+			peekable := r.peekableCards[:0]
+			for _, typ := range r.peekableCards {
+				if !r.state.Deck[typ].IsMagic {
+					peekable = append(peekable, typ)
+				}
+			}
+			r.peekableCards = peekable
+			for _, typ := range sortedDeckTypes(r.state.Deck) {
+				card := r.state.Deck[typ]
+				if card.IsMagic && card.Count != 0 {
+					card.Count = 0
+					r.state.Deck[typ] = card
+					r.out = append(r.out, simstep.DropCard{Name: typ.String()})
+				}
+			}
+		},
+	},
+
+	ModifierCowards: {
+		scorePercent: 80,
+		spawnCreep: func(creep *game.Creep) {
+			if creep.Type == game.CreepNone || creep.Traits.Has(game.TraitCoward) {
+				return
+			}
+			creep.Traits = appendTraits(creep.Traits, game.TraitCoward)
+		},
+	},
+
+	ModifierDoubleLoot: {
+		scorePercent: 75,
+		rule:         doubleLootRule{},
+	},
+
+	ModifierDragonEvery5: {
+		scorePercent: 150,
+		peekCreep: func(r *runner, round int, typ game.CreepType) game.CreepType {
+			if typ != game.CreepNone && round%5 == 0 {
+				return game.CreepDragon
+			}
+			return typ
+		},
+	},
+}
+
+func (r *runner) initModifiers() {
+	seen := make(map[Modifier]bool)
+	for _, m := range r.config.Modifiers {
+		hooks, ok := modifiers[m]
+		if !ok || seen[m] {
+			continue
+		}
+		seen[m] = true
+		r.modifiers = append(r.modifiers, hooks)
+	}
+}
+
+func (r *runner) modifyDeck() {
+	for _, m := range r.modifiers {
+		if m.initDeck != nil {
+			m.initDeck(r)
+		}
+	}
+}
+
+func (r *runner) modifyCreepType(round int, typ game.CreepType) game.CreepType {
+	for _, m := range r.modifiers {
+		if m.peekCreep != nil {
+			typ = m.peekCreep(r, round, typ)
+		}
+	}
+	return typ
+}
+
+// modifyDungeon replaces the map nodes creeps,
+// so the tactic can see them when it chooses a path.
+func (r *runner) modifyDungeon() {
+	for i, layer := range r.dungeon.layers {
+		for j := range layer {
+			node := &layer[j]
+			if node.creep == game.CreepNone {
+				continue
+			}
+			node.creep = r.modifyCreepType(i+1, node.creep)
+			if node.creep == game.CreepDragon {
+				node.PathOption = game.PathOption{Kind: game.PathBoss}
+			}
+		}
+	}
+}
+
+func (r *runner) modifyCreep(creep *game.Creep) {
+	for _, m := range r.modifiers {
+		if m.spawnCreep != nil {
+			m.spawnCreep(creep)
+		}
+	}
+}
+
+// applyScoreMultiplier gives (or takes) the modifiers score bonus.
+func (r *runner) applyScoreMultiplier() {
+	percent := 100
+	for _, m := range r.modifiers {
+		percent = percent * m.scorePercent / 100
+	}
+	bonus := r.state.Score*percent/100 - r.state.Score
+	if bonus == 0 {
+		return
+	}
+	r.state.Score += bonus
+	r.out = append(r.out, simstep.UpdateScore{Delta: bonus})
+	r.emitGreenLogf("Modifiers score multiplier is %d%%: %+d score points", percent, bonus)
+}
+
+type glassCannonRule struct{ ruleBase }
+
+func (glassCannonRule) OnDamageDealt(r *runner, dmg *damageEvent) {
+	dmg.amount *= 2
+}
+
+type doubleLootRule struct{ ruleBase }
+
+func (doubleLootRule) OnCreepDefeated(r *runner, defeat *defeatEvent) {
+	defeat.cardsReward *= 2
+}
+
+func sortedDeckTypes(deck map[game.CardType]game.Card) []game.CardType {
+	counts := make(map[game.CardType]int, len(deck))
+	for typ, card := range deck {
+		counts[typ] = card.Count
+	}
+	return sortedCardTypes(counts)
+}
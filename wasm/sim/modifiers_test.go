@@ -0,0 +1,160 @@
+package sim
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestParseModifier(t *testing.T) {
+	for _, m := range []Modifier{ModifierGlassCannon, ModifierNoMagic, ModifierCowards, ModifierDoubleLoot, ModifierDragonEvery5} {
+		parsed, ok := ParseModifier(m.String())
+		if !ok || parsed != m {
+			t.Errorf("ParseModifier(%q): have %v, want %v", m.String(), parsed, m)
+		}
+	}
+	if _, ok := ParseModifier("Unknown"); ok {
+		t.Errorf("ParseModifier(Unknown) succeeded")
+	}
+}
+
+func TestRunModifiers(t *testing.T) {
+	dragonPaths := 0
+	for seed := int64(0); seed < 10; seed++ {
+		tactic := &Tactic{
+			ChooseCard: func(s game.State) game.CardType {
+				if s.Avatar.MaxHP != 20 {
+					t.Fatalf("seed=%d: MaxHP is %d", seed, s.Avatar.MaxHP)
+				}
+				if s.Deck[game.CardMagicArrow].Count != 0 || s.Deck[game.CardFirebolt].Count != 0 {
+					t.Fatalf("seed=%d: magic cards are available", seed)
+				}
+				if !s.Creep.Traits.Has(game.TraitCoward) {
+					t.Fatalf("seed=%d: %s is not a coward", seed, s.Creep.Type)
+				}
+				if s.Round == 5 && s.Creep.Type != game.CreepDragon {
+					t.Fatalf("seed=%d: round 5 creep is %s", seed, s.Creep.Type)
+				}
+				return game.CardAttack
+			},
+		}
+		config := &Config{
+			AvatarHP: 40,
+			AvatarMP: 20,
+			Rounds:   10,
+			Seed:     seed,
+			Modifiers: []Modifier{
+				ModifierGlassCannon,
+				ModifierNoMagic,
+				ModifierCowards,
+				ModifierDragonEvery5,
+			},
+		}
+		RunTactic(config, tactic)
+
+		attack := &Tactic{
+			ChooseCard: func(s game.State) game.CardType {
+				return game.CardAttack
+			},
+		}
+		config = &Config{
+			AvatarHP: 100,
+			AvatarMP: 20,
+			Rounds:   10,
+			Seed:     seed,
+			// Duplicated modifiers are applied only once.
+			Modifiers: []Modifier{ModifierDoubleLoot, ModifierDoubleLoot},
+		}
+		out := RunTactic(config, attack)
+		score := 0
+		for i, a := range out {
+			switch a := a.(type) {
+			case simstep.GreenLog:
+				creep, ok := creepByPrefix(a.Message, " is defeated!")
+				if !ok {
+					continue
+				}
+				want := gamedata.GetCreepStats(creep).CardsReward * 2
+				have := countRewards(out[i+1:])
+				if have != want {
+					t.Fatalf("seed=%d: got %d cards for %s, want %d", seed, have, creep, want)
+				}
+			case simstep.UpdateScore:
+				if i == len(out)-2 {
+					// Score multiplier bonus is the last score update.
+					if want := score*75/100 - score; a.Delta != want {
+						t.Fatalf("seed=%d: got %d multiplier bonus, want %d", seed, a.Delta, want)
+					}
+				}
+				score += a.Delta
+			}
+		}
+
+		// The map shows the Dragon before the path is chosen.
+		mapTactic := &Tactic{
+			ChooseCard: func(s game.State) game.CardType {
+				if s.Round == 5 && s.Creep.Type != game.CreepNone && s.Creep.Type != game.CreepDragon {
+					t.Fatalf("seed=%d: round 5 creep is %s", seed, s.Creep.Type)
+				}
+				return game.CardAttack
+			},
+			ChoosePath: func(s game.State, paths []game.PathOption) int {
+				for i, path := range paths {
+					if s.Round == 4 && path.Kind == game.PathCreep {
+						t.Fatalf("seed=%d: round 5 path %d is %v", seed, i, path)
+					}
+					if s.Round == 4 && path.Kind == game.PathBoss {
+						dragonPaths++
+						return i
+					}
+				}
+				return 0
+			},
+		}
+		config = &Config{
+			AvatarHP:  200,
+			AvatarMP:  20,
+			Rounds:    10,
+			Seed:      seed,
+			MapMode:   true,
+			Modifiers: []Modifier{ModifierDragonEvery5},
+		}
+		checkNoPanic(t, RunTactic(config, mapTactic))
+	}
+	if dragonPaths == 0 {
+		t.Errorf("the map never had a Dragon on round 5")
+	}
+}
+
+// creepByPrefix parses a creep name that is followed by the suffix.
+func creepByPrefix(s, suffix string) (game.CreepType, bool) {
+	i := strings.Index(s, suffix)
+	if i == -1 {
+		return game.CreepNone, false
+	}
+	name := s[:i]
+	for typ := game.CreepCheepy; typ <= game.CreepGhost; typ++ {
+		if typ.String() == name {
+			return typ, true
+		}
+	}
+	return game.CreepNone, false
+}
+
+// countRewards counts the collected cards right after the creep is defeated.
+func countRewards(actions []simstep.Action) int {
+	n := 0
+	for _, a := range actions {
+		switch a := a.(type) {
+		case simstep.ChangeCardCount:
+			n += a.Delta
+		case simstep.GreenLog, simstep.UpdateScore:
+		default:
+			return n
+		}
+	}
+	return n
+}
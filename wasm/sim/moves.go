@@ -0,0 +1,142 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// IllegalMovePolicy describes how the illegal card moves are handled.
+type IllegalMovePolicy int
+
+const (
+	// IllegalMoveSkip wastes the turn: the creep moves as usual.
+	// The game is over after 10 illegal moves.
+	IllegalMoveSkip IllegalMovePolicy = iota
+
+	// IllegalMoveStrict ends the game after the first illegal move.
+	IllegalMoveStrict
+
+	// IllegalMoveLenient plays the lenientCard instead of the illegal move.
+	// Substituted moves don't count towards the illegal moves limit.
+	IllegalMoveLenient
+)
+
+// ParseIllegalMovePolicy returns a policy by its name:
+// "skip", "strict" or "lenient".
+func ParseIllegalMovePolicy(name string) (IllegalMovePolicy, bool) {
+	switch name {
+	case "skip":
+		return IllegalMoveSkip, true
+	case "strict":
+		return IllegalMoveStrict, true
+	case "lenient":
+		return IllegalMoveLenient, true
+	default:
+		return IllegalMoveSkip, false
+	}
+}
+
+// lenientCard is a card that is played instead of the illegal move
+// in the IllegalMoveLenient mode.
+const lenientCard = game.CardAttack
+
+// moveRejection is a reason why the card can't be played.
+type moveRejection int
+
+const (
+	moveOK moveRejection = iota
+
+	// moveUnknownCard means that the card is not a part of the deck.
+	moveUnknownCard
+
+	// moveUnavailable means that there are no such cards left.
+	moveUnavailable
+
+	// moveNoMana means that the card costs more MP than the avatar has.
+	moveNoMana
+
+	// moveUnsupported means that the card can't be used in this game mode.
+	moveUnsupported
+)
+
+func (reason moveRejection) String() string {
+	switch reason {
+	case moveUnknownCard:
+		return "unknown card"
+	case moveUnavailable:
+		return "unavailable card"
+	case moveNoMana:
+		return "not enough mana"
+	case moveUnsupported:
+		return "unsupported card"
+	default:
+		return "ok"
+	}
+}
+
+// maxBadMoves returns a number of illegal moves that ends the game.
+func (policy IllegalMovePolicy) maxBadMoves() int {
+	if policy == IllegalMoveStrict {
+		return 1
+	}
+	return 10
+}
+
+// validateCardMove checks the card availability and its cost.
+// It's shared by the game and duel runners.
+func validateCardMove(deck map[game.CardType]game.Card, avatar *game.Avatar, cardType game.CardType) moveRejection {
+	card, ok := deck[cardType]
+	switch {
+	case !ok:
+		return moveUnknownCard
+	case card.Count == 0:
+		return moveUnavailable
+	case avatar.MP < card.MP:
+		return moveNoMana
+	default:
+		return moveOK
+	}
+}
+
+// validateMove checks the whole card move without changing the game state.
+func (r *runner) validateMove(cardType game.CardType) moveRejection {
+	return validateCardMove(r.state.Deck, &r.state.Avatar, cardType)
+}
+
+func (b *simBase) recordIllegalMove(cardType game.CardType, reason moveRejection) {
+	b.out = append(b.out, simstep.IllegalMove{
+		Card:   cardType.String(),
+		Reason: reason.String(),
+	})
+}
+
+// substituteMove returns a card to play instead of the illegal move, if the policy permits it.
+// The valid func reports whether the substitute can be played.
+func (b *simBase) substituteMove(policy IllegalMovePolicy, valid func(game.CardType) bool) (game.CardType, bool) {
+	if policy == IllegalMoveLenient && valid(lenientCard) {
+		b.emitLogf("Playing %s instead", lenientCard.String())
+		return lenientCard, true
+	}
+	return 0, false
+}
+
+// rejectMove reports the illegal move.
+// It returns a card to play instead, if the policy permits it.
+func (r *runner) rejectMove(cardType game.CardType, reason moveRejection) (game.CardType, bool) {
+	r.recordIllegalMove(cardType, reason)
+	switch reason {
+	case moveUnknownCard:
+		r.emitRedLogf("Tried to use unknown card %s", cardType.String())
+	case moveUnavailable:
+		r.emitRedLogf("Tried to use unavailable card %s", cardType.String())
+	case moveNoMana:
+		r.emitRedLogf("Not enough mana to use %s", cardType.String())
+	}
+
+	valid := func(typ game.CardType) bool { return r.validateMove(typ) == moveOK }
+	if substitute, ok := r.substituteMove(r.config.IllegalMoves, valid); ok {
+		return substitute, true
+	}
+	r.badMoves++
+	return cardType, false
+}
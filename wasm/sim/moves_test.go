@@ -0,0 +1,106 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestIllegalMoves(t *testing.T) {
+	t.Run("NoResourcesConsumed", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCard(game.CardHeal))
+		changeDeckCardCount(r.state.Deck, game.CardHeal, 1)
+		r.state.Avatar.MP = 1
+		r.runTurn()
+		if r.state.Deck[game.CardHeal].Count != 1 || r.state.Avatar.MP != 1 {
+			t.Errorf("rejected Heal: have %d cards and %d MP", r.state.Deck[game.CardHeal].Count, r.state.Avatar.MP)
+		}
+		if r.badMoves != 1 {
+			t.Errorf("have %d bad moves, want 1", r.badMoves)
+		}
+		move, ok := findIllegalMove(r.out)
+		if !ok || move.Card != "Heal" || move.Reason != moveNoMana.String() {
+			t.Errorf("have %+v illegal move report", move)
+		}
+	})
+
+	t.Run("UnknownCard", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCard(game.CardType(100)))
+		r.runTurn()
+		if move, ok := findIllegalMove(r.out); !ok || move.Reason != moveUnknownCard.String() {
+			t.Errorf("have %+v illegal move report", move)
+		}
+	})
+
+	t.Run("RejectedParry", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCard(game.CardParry))
+		r.runTurn()
+		if r.state.Creep.HP != r.state.Creep.MaxHP || r.state.Avatar.HP == 40 {
+			t.Errorf("unavailable Parry reflected the attack")
+		}
+	})
+
+	t.Run("NothingToParry", func(t *testing.T) {
+		// Parry is a legal move that does nothing when the creep is not attacking.
+		for _, intent := range []game.CreepIntent{game.IntentAttack, game.IntentDefend} {
+			r := newTestRunner(t, &Config{Seed: 1}, withCard(game.CardParry))
+			changeDeckCardCount(r.state.Deck, game.CardParry, 1)
+			r.state.Creep.Intent = intent
+			if intent == game.IntentAttack {
+				r.state.Creep.Stun = 1
+			}
+			st := cloneState(r.state)
+			if !st.Can(game.CardParry) {
+				t.Errorf("%s intent: State.Can rejects a Parry", intent)
+			}
+			r.runTurn()
+			if r.state.Deck[game.CardParry].Count != 0 || r.badMoves != 0 {
+				t.Errorf("%s intent: have %d Parry cards and %d bad moves", intent, r.state.Deck[game.CardParry].Count, r.badMoves)
+			}
+			if move, ok := findIllegalMove(r.out); ok {
+				t.Errorf("%s intent: have %+v illegal move report", intent, move)
+			}
+		}
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1, IllegalMoves: IllegalMoveStrict}, withCard(game.CardHeal))
+		r.Run()
+		if r.state.Turn > 2 || r.badMoves != 1 {
+			t.Errorf("strict mode: game lasted for %d turns with %d bad moves", r.state.Turn, r.badMoves)
+		}
+	})
+
+	t.Run("Lenient", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1, IllegalMoves: IllegalMoveLenient}, withCard(game.CardHeal))
+		r.runTurn()
+		if r.state.Creep.HP == r.state.Creep.MaxHP || r.badMoves != 0 {
+			t.Errorf("lenient mode: have %d creep HP and %d bad moves", r.state.Creep.HP, r.badMoves)
+		}
+		if _, ok := findIllegalMove(r.out); !ok {
+			t.Errorf("substituted move is not reported")
+		}
+	})
+
+	t.Run("ParsePolicy", func(t *testing.T) {
+		for _, name := range []string{"skip", "strict", "lenient"} {
+			if _, ok := ParseIllegalMovePolicy(name); !ok {
+				t.Errorf("can't parse %q policy", name)
+			}
+		}
+		if _, ok := ParseIllegalMovePolicy("forgiving"); ok {
+			t.Errorf("parsed unknown policy")
+		}
+	})
+}
+
+// findIllegalMove returns the first illegal move report.
+func findIllegalMove(actions []simstep.Action) (simstep.IllegalMove, bool) {
+	for _, a := range actions {
+		if a, ok := a.(simstep.IllegalMove); ok {
+			return a, true
+		}
+	}
+	return simstep.IllegalMove{}, false
+}
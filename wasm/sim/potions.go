@@ -0,0 +1,84 @@
+package sim
+
+import (
+	"strings"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// potionTurn is a pseudo card type that is used when a potion takes the turn.
+// It has no card effects and is never played.
+const potionTurn game.CardType = -1
+
+// usePotion asks the tactic to use a potion before the card is chosen.
+// It reports whether the used potion took the turn.
+func (r *runner) usePotion() bool {
+	if !r.config.Potions || r.tactic.UsePotion == nil || len(r.state.Potions) == 0 {
+		return false
+	}
+	typ := r.tactic.UsePotion(cloneState(r.state))
+	if typ == game.PotionNone {
+		return false
+	}
+	i := r.findPotion(typ)
+	if i == -1 {
+		r.emitRedLogf("Tried to use unavailable %s potion", typ.String())
+		r.badMoves++
+		return false
+	}
+	potion := r.state.Potions[i]
+	r.setPotions(append(r.state.Potions[:i:i], r.state.Potions[i+1:]...))
+
+	avatar := &r.state.Avatar
+	name := typ.String() + " potion"
+	switch typ {
+	case game.PotionHealing, game.PotionGreaterHealing:
+		r.avatarHeal(name, r.rangeRand(potion.Power))
+	case game.PotionMana:
+		gained := calculateHealed(r.rangeRand(potion.Power), avatar.MP, avatar.MaxMP)
+		avatar.MP += gained
+		r.out = append(r.out, simstep.UpdateMP{Delta: gained})
+		r.emitGreenLogf("Got %d MP from %s", gained, name)
+	case game.PotionStoneskin:
+		r.applyStatus(gamedata.StatusBlock, r.rangeRand(potion.Power))
+	}
+
+	return !potion.FreeAction
+}
+
+func (r *runner) findPotion(typ game.PotionType) int {
+	for i, p := range r.state.Potions {
+		if p.Type == typ {
+			return i
+		}
+	}
+	return -1
+}
+
+// dropPotions rolls the creep potion drop table.
+// Potions that don't fit into the inventory are lost.
+func (r *runner) dropPotions(typ game.CreepType) {
+	for _, drop := range gamedata.PotionDrops[typ] {
+		if r.rand.Intn(100) >= drop.Chance {
+			continue
+		}
+		if len(r.state.Potions) == gamedata.MaxPotions {
+			r.emitRedLogf("Left %s potion behind: the inventory is full", drop.Potion.String())
+			continue
+		}
+		r.emitGreenLogf("Collected %s potion", drop.Potion.String())
+		potion := game.Potion{Type: drop.Potion, PotionStats: gamedata.GetPotionStats(drop.Potion)}
+		r.setPotions(append(r.state.Potions, potion))
+	}
+}
+
+func (r *runner) setPotions(potions []game.Potion) {
+	r.state.Potions = potions
+	names := make([]string, len(potions))
+	for i, p := range potions {
+		names[i] = p.Type.String()
+	}
+	r.out = append(r.out, simstep.SetPotions{Names: strings.Join(names, ", ")})
+}
@@ -0,0 +1,78 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+func TestPotions(t *testing.T) {
+	newPotion := func(typ game.PotionType) game.Potion {
+		return game.Potion{Type: typ, PotionStats: gamedata.GetPotionStats(typ)}
+	}
+
+	t.Run("Drops", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1, Potions: true})
+		for i := 0; i < 20; i++ {
+			r.dropPotions(game.CreepGolem)
+		}
+		if len(r.state.Potions) != gamedata.MaxPotions {
+			t.Errorf("have %d potions, want %d", len(r.state.Potions), gamedata.MaxPotions)
+		}
+	})
+
+	t.Run("FreeAction", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1, Potions: true}, withTactic(&Tactic{
+			ChooseCard: func(game.State) game.CardType { return game.CardMagicArrow },
+			UsePotion:  func(game.State) game.PotionType { return game.PotionHealing },
+		}))
+		r.state.Avatar.HP = 20
+		r.state.Potions = []game.Potion{newPotion(game.PotionHealing), newPotion(game.PotionMana)}
+		r.runTurn()
+		if r.state.Creep.HP != 10-3 {
+			t.Errorf("card is not played after a free action potion")
+		}
+		if len(r.state.Potions) != 1 || r.state.Potions[0].Type != game.PotionMana {
+			t.Errorf("healing potion is not consumed: %v", r.state.Potions)
+		}
+		if r.state.Avatar.HP <= 20-gamedata.GetCreepStats(game.CreepLion).Damage.High() {
+			t.Errorf("avatar is not healed: have %d HP", r.state.Avatar.HP)
+		}
+		r.runTurn()
+		if r.badMoves != 1 {
+			t.Errorf("unavailable potion is not a bad move")
+		}
+	})
+
+	t.Run("TakesTurn", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1, Potions: true}, withTactic(&Tactic{
+			ChooseCard: func(game.State) game.CardType {
+				t.Fatalf("card is chosen after the potion that takes the turn")
+				return game.CardAttack
+			},
+			UsePotion: func(game.State) game.PotionType { return game.PotionGreaterHealing },
+		}))
+		r.state.Avatar.HP = 10
+		r.state.Potions = []game.Potion{newPotion(game.PotionGreaterHealing)}
+		r.runTurn()
+		if r.state.Creep.HP != 10 || len(r.state.Potions) != 0 {
+			t.Errorf("potion turn: have %d creep HP and %d potions", r.state.Creep.HP, len(r.state.Potions))
+		}
+		if r.state.Avatar.HP < 10+15-3 {
+			t.Errorf("avatar is not healed: have %d HP", r.state.Avatar.HP)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withTactic(&Tactic{
+			ChooseCard: func(game.State) game.CardType { return game.CardRest },
+			UsePotion: func(game.State) game.PotionType {
+				t.Fatalf("potions are used when they're disabled")
+				return game.PotionNone
+			},
+		}))
+		r.state.Potions = []game.Potion{newPotion(game.PotionHealing)}
+		r.runTurn()
+	})
+}
@@ -0,0 +1,257 @@
+package sim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// Scenario is a hand-crafted puzzle with a fixed starting state and a goal.
+type Scenario struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// AvatarHP and AvatarMP override the config values when non-zero.
+	AvatarHP int `json:"avatarHP"`
+	AvatarMP int `json:"avatarMP"`
+
+	// Cards maps a card name to its starting count.
+	// Unlisted limited cards are not available.
+	Cards map[string]int `json:"cards"`
+
+	// Creeps is a queue of creeps that are encountered in the given order.
+	Creeps []string `json:"creeps"`
+
+	// Rolls is a predetermined roll sequence.
+	// Every roll is an offset from the lowest possible value,
+	// so 0 is the minimal damage roll.
+	// Rolls that are out of range are clamped.
+	// When rolls are exhausted, every roll gives the lowest value.
+	Rolls []int `json:"rolls"`
+
+	Goal PuzzleGoal `json:"goal"`
+}
+
+// PuzzleGoal is a puzzle win condition.
+type PuzzleGoal struct {
+	// Defeat is a creep that should be defeated to solve the puzzle.
+	// Empty value means that all creeps should be defeated.
+	Defeat string `json:"defeat"`
+
+	// MaxTurns is a turn limit to achieve the goal.
+	// Zero value means "no limit".
+	MaxTurns int `json:"maxTurns"`
+}
+
+// ParseScenario decodes a JSON scenario and validates it.
+func ParseScenario(data []byte) (*Scenario, error) {
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, err
+	}
+	if _, err := newPuzzleRun(&scenario); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// RunPuzzle runs a puzzle scenario instead of a dungeon run.
+// Config AvatarHP and AvatarMP are used as defaults and as a UI baseline.
+func RunPuzzle(config *Config, scenario *Scenario, tactic *Tactic) []simstep.Action {
+	puzzle, err := newPuzzleRun(scenario)
+	if err != nil {
+		return []simstep.Action{simstep.RedLog{Message: "Invalid puzzle: " + err.Error()}}
+	}
+	puzzleConfig := &Config{
+		AvatarHP: config.AvatarHP,
+		AvatarMP: config.AvatarMP,
+		Rounds:   len(puzzle.creeps),
+	}
+	runner := newRunner(puzzleConfig, tactic)
+	runner.rand = &scriptedRolls{rolls: scenario.Rolls}
+	runner.puzzle = puzzle
+	return runner.Run()
+}
+
+type puzzleRun struct {
+	scenario *Scenario
+	creeps   []game.CreepType
+	cards    map[game.CardType]int
+
+	// target is CreepNone if all creeps should be defeated.
+	target game.CreepType
+
+	defeated int
+	solved   bool
+}
+
+func newPuzzleRun(scenario *Scenario) (*puzzleRun, error) {
+	p := &puzzleRun{
+		scenario: scenario,
+		cards:    make(map[game.CardType]int, len(scenario.Cards)),
+	}
+
+	if len(scenario.Creeps) == 0 {
+		return nil, errors.New("empty creeps queue")
+	}
+	for _, name := range scenario.Creeps {
+		typ, ok := parseCreepType(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown creep %q", name)
+		}
+		p.creeps = append(p.creeps, typ)
+	}
+
+	for name, n := range scenario.Cards {
+		typ, ok := parseCardType(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown card %q", name)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("negative %s count", name)
+		}
+		p.cards[typ] = n
+	}
+
+	if scenario.Goal.Defeat != "" {
+		typ, ok := parseCreepType(scenario.Goal.Defeat)
+		if !ok {
+			return nil, fmt.Errorf("unknown goal creep %q", scenario.Goal.Defeat)
+		}
+		if !p.hasCreep(typ) {
+			// The puzzle could never be solved.
+			return nil, fmt.Errorf("goal creep %s is not in the creeps queue", typ)
+		}
+		p.target = typ
+	}
+	if scenario.Goal.MaxTurns < 0 {
+		return nil, errors.New("negative goal turns limit")
+	}
+
+	return p, nil
+}
+
+func (p *puzzleRun) hasCreep(typ game.CreepType) bool {
+	for _, x := range p.creeps {
+		if x == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *puzzleRun) creepDefeated(typ game.CreepType, turn int) {
+	p.defeated++
+	if p.scenario.Goal.MaxTurns != 0 && turn > p.scenario.Goal.MaxTurns {
+		return
+	}
+	if p.target == typ || p.target == game.CreepNone && p.defeated == len(p.creeps) {
+		p.solved = true
+	}
+}
+
+// initPuzzle is an initWorld replacement for puzzles.
+func (r *runner) initPuzzle() {
+	r.initDeck()
+
+	scenario := r.puzzle.scenario
+	avatar := &r.state.Avatar
+	if scenario.AvatarHP != 0 {
+		r.out = append(r.out, simstep.UpdateHP{Delta: scenario.AvatarHP - avatar.HP})
+		avatar.HP = scenario.AvatarHP
+		avatar.MaxHP = scenario.AvatarHP
+	}
+	if scenario.AvatarMP != 0 {
+		r.out = append(r.out, simstep.UpdateMP{Delta: scenario.AvatarMP - avatar.MP})
+		avatar.MP = scenario.AvatarMP
+		avatar.MaxMP = scenario.AvatarMP
+	}
+
+	for _, typ := range sortedCardTypes(r.puzzle.cards) {
+		n := r.puzzle.cards[typ]
+		r.out = append(r.out, simstep.SetCardCount{Name: typ.String(), Count: n})
+		card := r.state.Deck[typ]
+		card.Count = n
+		r.state.Deck[typ] = card
+	}
+
+	r.state.Creep = r.newCreep(r.peekCreep(1), 1)
+	r.state.NextCreep = r.peekCreep(2)
+	r.out = append(r.out, simstep.SetCreep{
+		Name: r.state.Creep.Type.String(),
+		HP:   r.state.Creep.HP,
+	})
+	r.out = append(r.out, simstep.SetNextCreep{
+		Name: r.state.NextCreep.String(),
+		HP:   r.creepStats(r.state.NextCreep, 2).MaxHP,
+	})
+	r.emitLogf("Puzzle: %s", scenario.Name)
+	if scenario.Description != "" {
+		r.emitLogf("%s", scenario.Description)
+	}
+}
+
+// puzzleOver reports whether the puzzle can't be continued.
+func (r *runner) puzzleOver() bool {
+	if r.puzzle.solved {
+		return true
+	}
+	maxTurns := r.puzzle.scenario.Goal.MaxTurns
+	return maxTurns != 0 && r.state.Turn > maxTurns
+}
+
+func (r *runner) judgePuzzle() {
+	r.out = append(r.out, simstep.PuzzleResult{Solved: r.puzzle.solved})
+	if r.puzzle.solved {
+		r.emitGreenLogf("Puzzle solved in %d turns!", r.state.Turn-1)
+	} else {
+		r.emitRedLogf("Puzzle failed")
+	}
+}
+
+// scriptedRolls is a roller that replays a predetermined roll sequence.
+type scriptedRolls struct {
+	rolls []int
+	pos   int
+}
+
+func (s *scriptedRolls) Intn(n int) int {
+	if s.pos >= len(s.rolls) {
+		return 0
+	}
+	roll := s.rolls[s.pos]
+	s.pos++
+	switch {
+	case roll < 0:
+		return 0
+	case roll >= n:
+		return n - 1
+	default:
+		return roll
+	}
+}
+
+// Shuffle keeps the original order to make the puzzles predictable.
+func (s *scriptedRolls) Shuffle(n int, swap func(i, j int)) {}
+
+func parseCreepType(name string) (game.CreepType, bool) {
+	for typ := game.CreepCheepy; gamedata.GetCreepStats(typ).MaxHP != 0; typ++ {
+		if typ.String() == name {
+			return typ, true
+		}
+	}
+	return game.CreepNone, false
+}
+
+func parseCardType(name string) (game.CardType, bool) {
+	for typ := range gamedata.Cards {
+		if typ.String() == name {
+			return typ, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,92 @@
+package sim
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+func TestParseScenario(t *testing.T) {
+	tests := []struct {
+		data string
+		err  string
+	}{
+		{`{"creeps": ["Imp"]}`, ""},
+		{`{"creeps": []}`, "empty creeps queue"},
+		{`{"creeps": ["Goblin"]}`, `unknown creep "Goblin"`},
+		{`{"creeps": ["Imp"], "cards": {"Fireball": 1}}`, `unknown card "Fireball"`},
+		{`{"creeps": ["Imp"], "cards": {"Firebolt": -1}}`, "negative Firebolt count"},
+		{`{"creeps": ["Imp"], "goal": {"defeat": "Goblin"}}`, `unknown goal creep "Goblin"`},
+		{`{"creeps": ["Imp", "Lion"], "goal": {"defeat": "Lion"}}`, ""},
+		{`{"creeps": ["Imp"], "goal": {"defeat": "Dragon"}}`, "goal creep Dragon is not in the creeps queue"},
+		{`{"creeps": ["Imp"], "goal": {"maxTurns": -1}}`, "negative goal turns limit"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseScenario([]byte(test.data))
+		have := ""
+		if err != nil {
+			have = err.Error()
+		}
+		if have != test.err {
+			t.Errorf("ParseScenario(%s): have %q error, want %q", test.data, have, test.err)
+		}
+	}
+}
+
+func TestRunPuzzles(t *testing.T) {
+	// Every shipped puzzle should be solved by its reference solution
+	// and should not be solved by a naive tactic.
+	solutions := map[string][]game.CardType{
+		"dragon_rush": {
+			game.CardParry, game.CardStun, game.CardPowerAttack, game.CardPowerAttack,
+			game.CardParry, game.CardPowerAttack, game.CardAttack,
+		},
+		"mummy_fire": {game.CardFirebolt, game.CardFirebolt},
+	}
+
+	solved := func(out []simstep.Action) bool {
+		for _, a := range out {
+			if a, ok := a.(simstep.PuzzleResult); ok {
+				return a.Solved
+			}
+		}
+		t.Fatal("no puzzle result")
+		return false
+	}
+
+	for name, moves := range solutions {
+		data, err := ioutil.ReadFile(filepath.Join("..", "..", "www", "puzzles", name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scenario, err := ParseScenario(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		config := &Config{AvatarHP: 40, AvatarMP: 20}
+
+		turn := 0
+		solution := &Tactic{
+			ChooseCard: func(s game.State) game.CardType {
+				turn++
+				return moves[turn-1]
+			},
+		}
+		if !solved(RunPuzzle(config, scenario, solution)) {
+			t.Errorf("%s: reference solution failed", name)
+		}
+
+		naive := &Tactic{
+			ChooseCard: func(s game.State) game.CardType {
+				return game.CardAttack
+			},
+		}
+		if solved(RunPuzzle(config, scenario, naive)) {
+			t.Errorf("%s: solved by always attacking", name)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// retreat resolves the retreat card after the creep move.
+// It reports whether the avatar leaves the current round.
+//
+// Without Config.RiskyRetreat the retreat always succeeds and costs nothing.
+func (r *runner) retreat(play *cardPlay) bool {
+	creep := &r.state.Creep
+
+	if !r.config.RiskyRetreat {
+		r.emitLogf("Retreated from %s!", creep.Type.String())
+		return true
+	}
+
+	chance := gamedata.RetreatChance
+	switch {
+	case play.evade || creep.Traits.Has(game.TraitSlow):
+		chance = 100
+	case creep.Traits.Has(game.TraitFast):
+		chance = gamedata.FastRetreatChance
+	}
+	if chance < 100 && r.rand.Intn(100) >= chance {
+		r.emitRedLogf("Failed to retreat from %s!", creep.Type.String())
+		return false
+	}
+
+	r.emitLogf("Retreated from %s!", creep.Type.String())
+	penalty := creep.ScoreReward * gamedata.RetreatPenaltyPercent / 100
+	if penalty > r.state.Score {
+		// Score never goes below zero.
+		penalty = r.state.Score
+	}
+	if penalty != 0 {
+		r.state.Score -= penalty
+		r.out = append(r.out, simstep.UpdateScore{Delta: -penalty})
+		r.emitRedLogf("Lost %d score points for retreating", penalty)
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+func TestRiskyRetreat(t *testing.T) {
+	t.Run("Classic", func(t *testing.T) {
+		for seed := int64(0); seed < 20; seed++ {
+			r := newTestRunner(t, &Config{Seed: seed}, withCreep(game.CreepLion), withCard(game.CardRetreat))
+			r.state.Score = 10
+			if r.state.Creep.Traits.Has(game.TraitFast) {
+				t.Fatalf("Lion is Fast without the risky retreat")
+			}
+			r.runTurn()
+			if r.state.Round != 2 || r.state.Score != 10 {
+				t.Fatalf("seed %d: have round %d and %d score", seed, r.state.Round, r.state.Score)
+			}
+		}
+	})
+
+	t.Run("Slow", func(t *testing.T) {
+		for seed := int64(0); seed < 20; seed++ {
+			r := newTestRunner(t, &Config{Seed: seed, RiskyRetreat: true}, withCreep(game.CreepMummy), withCard(game.CardRetreat))
+			r.state.Score = 10
+			r.runTurn()
+			if r.state.Round != 2 || r.state.Avatar.HP != 40 {
+				t.Fatalf("seed %d: have round %d and %d HP", seed, r.state.Round, r.state.Avatar.HP)
+			}
+			if want := 10 - 15*gamedata.RetreatPenaltyPercent/100; r.state.Score != want {
+				t.Fatalf("seed %d: have %d score, want %d", seed, r.state.Score, want)
+			}
+		}
+	})
+
+	t.Run("Fast", func(t *testing.T) {
+		failed := 0
+		for seed := int64(0); seed < 20; seed++ {
+			r := newTestRunner(t, &Config{Seed: seed, RiskyRetreat: true}, withCreep(game.CreepLion), withCard(game.CardRetreat))
+			r.state.Score = 10
+			r.runTurn()
+			if r.state.Round == 1 {
+				failed++
+			}
+			if damage := 40 - r.state.Avatar.HP; damage < 2*2 {
+				t.Fatalf("seed %d: have %d damage, want a heavy attack", seed, damage)
+			}
+		}
+		if failed == 0 || failed == 20 {
+			t.Errorf("have %d failed retreats out of 20", failed)
+		}
+	})
+}
@@ -0,0 +1,163 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+// rule is a self-contained game mechanic that hooks into the runner.
+// Cards, creep traits and modifiers are implemented as rules,
+// see effectCard, traitRules and modifierHooks.rule.
+//
+// Rules that don't need some hooks should embed ruleBase.
+type rule interface {
+	// OnTurnStart is called before the tactic selects a card.
+	OnTurnStart(r *runner)
+
+	// OnCardPlayed is called after the card costs are paid.
+	// For the played card rule, it performs the card effect.
+	// Other rules are called before that and can cancel the play.
+	OnCardPlayed(r *runner, play *cardPlay)
+
+	// OnCreepTurn is called before the creep action.
+	// It can make the creep skip its action.
+	OnCreepTurn(r *runner, turn *creepTurn)
+
+	// OnDamageDealt can change the damage dealt to the creep by the avatar.
+	OnDamageDealt(r *runner, dmg *damageEvent)
+
+	// OnDamageTaken can change the damage dealt to the avatar by the creep.
+	OnDamageTaken(r *runner, dmg *damageEvent)
+
+	// OnCreepDefeated is called before the creep rewards are given.
+	OnCreepDefeated(r *runner, defeat *defeatEvent)
+
+	// OnRoundStart is called after the round creep is spawned.
+	OnRoundStart(r *runner)
+}
+
+// ruleBase implements all rule hooks as no-ops.
+type ruleBase struct{}
+
+func (ruleBase) OnTurnStart(r *runner)                          {}
+func (ruleBase) OnCardPlayed(r *runner, play *cardPlay)         {}
+func (ruleBase) OnCreepTurn(r *runner, turn *creepTurn)         {}
+func (ruleBase) OnDamageDealt(r *runner, dmg *damageEvent)      {}
+func (ruleBase) OnDamageTaken(r *runner, dmg *damageEvent)      {}
+func (ruleBase) OnCreepDefeated(r *runner, defeat *defeatEvent) {}
+func (ruleBase) OnRoundStart(r *runner)                         {}
+
+type cardPlay struct {
+	cardType game.CardType
+	card     game.CardStats
+
+	// damage is a total damage dealt by the card effects so far.
+	damage int
+
+	// evade makes the creep skip its move.
+	evade bool
+
+	// combo is a combo completed by this card, if any.
+	combo *gamedata.Combo
+
+	// canceled prevents the card effect.
+	canceled bool
+}
+
+type creepTurn struct {
+	// play is the card that was selected by the tactic during this turn.
+	play *cardPlay
+
+	// played reports whether the card costs were paid.
+	played bool
+
+	// skip prevents the creep action.
+	skip bool
+}
+
+type damageEvent struct {
+	// cardType is a card that deals the damage.
+	// It's only meaningful for OnDamageDealt.
+	cardType game.CardType
+
+	element game.Element
+
+	amount int
+}
+
+type defeatEvent struct {
+	creep *game.Creep
+
+	// cardsReward is a number of cards that will be collected.
+	cardsReward int
+
+	// canceled keeps the creep alive.
+	// The creep HP should be restored by the rule that cancels the defeat.
+	canceled bool
+}
+
+// activeRules returns the current creep traits and modifiers rules.
+// Card rules are only activated when the card is played.
+func (r *runner) activeRules() []rule {
+	var rules []rule
+	for _, trait := range r.state.Creep.Traits {
+		if rule, ok := traitRules[trait]; ok {
+			rules = append(rules, rule)
+		}
+	}
+	for _, m := range r.modifiers {
+		if m.rule != nil {
+			rules = append(rules, m.rule)
+		}
+	}
+	return rules
+}
+
+func (r *runner) onTurnStart() {
+	for _, rule := range r.activeRules() {
+		rule.OnTurnStart(r)
+	}
+}
+
+func (r *runner) onCardPlayed(play *cardPlay) {
+	for _, rule := range r.activeRules() {
+		rule.OnCardPlayed(r, play)
+		if play.canceled {
+			return
+		}
+	}
+	effectCard{}.OnCardPlayed(r, play)
+	if play.combo != nil {
+		r.applyComboEffects(play)
+	}
+}
+
+func (r *runner) onCreepTurn(turn *creepTurn) {
+	for _, rule := range r.activeRules() {
+		rule.OnCreepTurn(r, turn)
+	}
+}
+
+func (r *runner) onDamageDealt(dmg *damageEvent) {
+	for _, rule := range r.activeRules() {
+		rule.OnDamageDealt(r, dmg)
+	}
+}
+
+func (r *runner) onDamageTaken(dmg *damageEvent) {
+	for _, rule := range r.activeRules() {
+		rule.OnDamageTaken(r, dmg)
+	}
+}
+
+func (r *runner) onCreepDefeated(defeat *defeatEvent) {
+	for _, rule := range r.activeRules() {
+		rule.OnCreepDefeated(r, defeat)
+	}
+}
+
+func (r *runner) onRoundStart() {
+	for _, rule := range r.activeRules() {
+		rule.OnRoundStart(r)
+	}
+}
@@ -0,0 +1,27 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+func TestRuleRegistry(t *testing.T) {
+	for typ := range gamedata.Cards {
+		if len(gamedata.GetCardEffects(typ)) == 0 {
+			t.Errorf("%s card has no effects", typ)
+		}
+	}
+	for trait := game.TraitCoward; trait <= game.TraitFast; trait++ {
+		switch trait {
+		case game.TraitWeakToFire, game.TraitIncorporeal:
+			continue // Implemented as resistances
+		case game.TraitRanged:
+			continue // Checked by the creep attack
+		}
+		if _, ok := traitRules[trait]; !ok {
+			t.Errorf("%s trait has no rule", trait)
+		}
+	}
+}
@@ -3,7 +3,6 @@ package sim
 import (
 	"fmt"
 	"math/rand"
-	"runtime/debug"
 	"sort"
 
 	"github.com/quasilyte/gophers-and-dragons/game"
@@ -16,17 +15,116 @@ type Config struct {
 	AvatarMP int
 	Rounds   int
 	Seed     int64
+
+	// Class is an avatar class to play.
+	// Tactic.ChooseClass has a priority over this setting.
+	Class game.AvatarClass
+
+	// Leveling enables avatar experience and levels.
+	// Level-up perks are selected by the Tactic.ChoosePerk.
+	Leveling bool
+
+	// Draft enables a deck building phase before the run.
+	// The starting deck is selected by the Tactic.BuildDeck.
+	Draft bool
+
+	// Bosses enables scripted boss abilities.
+	// Boss creep intents are announced one move ahead.
+	Bosses bool
+
+	// Intents enables creep intents for regular creeps.
+	// Creep intents are announced one move ahead.
+	Intents bool
+
+	// MapMode enables a branching dungeon map.
+	// The next encounter is selected by the Tactic.ChoosePath.
+	// It's ignored in the endless mode.
+	MapMode bool
+
+	// Endless enables a mode where rounds continue until the avatar dies.
+	// Rounds field is ignored in this mode.
+	Endless bool
+
+	// BossEvery specifies how often the Dragon is encountered in the endless mode.
+	// Zero value means "never".
+	BossEvery int
+
+	// Scaling describes how creep stats grow in the endless mode.
+	Scaling ScalingCurve
+
+	// BestScore is the endless mode high-water mark of the previous runs.
+	// The updated mark is reported via simstep.EndlessResult.
+	BestScore int
+
+	// Modifiers is a list of run mutators.
+	// Every modifier affects the final score.
+	Modifiers []Modifier
+
+	// CombatRolls enables misses and critical hits.
+	// See game.CombatStats.
+	CombatRolls bool
+
+	// ExpansionCards adds the expansion cards, like CardBlock, to the rewards.
+	ExpansionCards bool
+
+	// Combos enables bonus effects for card sequences.
+	// See gamedata.Combos.
+	Combos bool
+
+	// Potions enables the potion drops and the Tactic.UsePotion.
+	// See gamedata.PotionDrops.
+	Potions bool
+
+	// RiskyRetreat makes the retreat fail sometimes and cost score points.
+	// See gamedata.RetreatChance.
+	RiskyRetreat bool
+
+	// IllegalMoves selects how the illegal card moves are handled.
+	// The zero value is IllegalMoveSkip.
+	IllegalMoves IllegalMovePolicy
+}
+
+// Tactic is a set of user-provided functions that control the avatar.
+// Only ChooseCard is mandatory.
+type Tactic struct {
+	ChooseCard func(game.State) game.CardType
+
+	// ChoosePath returns an index of the selected path.
+	// If it's nil, the first path is always selected.
+	ChoosePath func(game.State, []game.PathOption) int
+
+	// BuildDeck returns a starting loadout for the draft phase.
+	// If it's nil, the default deck is used.
+	BuildDeck func(game.DraftOffer) game.Loadout
+
+	// ChooseClass returns the avatar class to play.
+	// If it's nil, Config.Class is used.
+	ChooseClass func() game.AvatarClass
+
+	// ChoosePerk returns one of the offered perks on level-up.
+	// If it's nil, the first offered perk is selected.
+	ChoosePerk func(game.State, []game.Perk) game.Perk
+
+	// UsePotion returns a potion to use before the ChooseCard call.
+	// game.PotionNone means that no potion is used.
+	// If it's nil, potions are never used.
+	UsePotion func(game.State) game.PotionType
 }
 
 func Run(config *Config, chooseCard func(game.State) game.CardType) []simstep.Action {
-	runner := newRunner(config, chooseCard)
+	return RunTactic(config, &Tactic{ChooseCard: chooseCard})
+}
+
+func RunTactic(config *Config, tactic *Tactic) []simstep.Action {
+	runner := newRunner(config, tactic)
 	return runner.Run()
 }
 
 func newGameState(config *Config) *game.State {
 	avatarStats := game.AvatarStats{
-		MaxHP: config.AvatarHP,
-		MaxMP: config.AvatarMP,
+		MaxHP:       config.AvatarHP,
+		MaxMP:       config.AvatarMP,
+		CombatStats: gamedata.AvatarCombatStats,
 	}
 	return &game.State{
 		Round: 1,
@@ -34,6 +132,7 @@ func newGameState(config *Config) *game.State {
 		Avatar: game.Avatar{
 			HP:          avatarStats.MaxHP,
 			MP:          avatarStats.MaxMP,
+			Level:       1,
 			AvatarStats: avatarStats,
 		},
 		Deck: make(map[game.CardType]game.Card),
@@ -41,39 +140,50 @@ func newGameState(config *Config) *game.State {
 }
 
 type runner struct {
+	simBase
+
 	state         *game.State
 	config        *Config
-	out           []simstep.Action
-	rand          *rand.Rand
-	chooseCard    func(game.State) game.CardType
+	tactic        *Tactic
+	master        *DungeonMaster
+	puzzle        *puzzleRun
+	campaign      *campaignRun
+	modifiers     []*modifierHooks
+	masterBudget  int
+	dungeon       *dungeonMap
 	peekableCards []game.CardType
 	badMoves      int
 }
 
-func newRunner(config *Config, chooseCard func(game.State) game.CardType) *runner {
-	return &runner{
-		config:     config,
-		state:      newGameState(config),
-		chooseCard: chooseCard,
-		rand:       rand.New(rand.NewSource(config.Seed)),
+func newRunner(config *Config, tactic *Tactic) *runner {
+	r := &runner{
+		simBase: simBase{rand: rand.New(rand.NewSource(config.Seed))},
+		config:  config,
+		state:   newGameState(config),
+		tactic:  tactic,
 	}
+	r.initModifiers()
+	return r
 }
 
 func (r *runner) Run() (out []simstep.Action) {
-	defer func() {
-		rv := recover()
-		if rv == nil {
-			return // OK
-		}
-		out = append(out, simstep.RedLog{Message: "Panic: " + fmt.Sprint(rv)})
-		// Print stack trace to the JS console.
-		println(string(debug.Stack()))
-	}()
+	defer recoverPanic(&out)
 
-	r.initWorld()
+	if err := r.config.validate(); err != nil {
+		r.emitRedLogf("Invalid config: %v", err)
+		return r.out
+	}
+	if r.puzzle != nil {
+		r.initPuzzle()
+	} else {
+		r.initWorld()
+	}
 	r.out = append(r.out, simstep.NextRound{})
 	for {
-		if r.badMoves >= 10 {
+		if r.puzzle != nil && r.puzzleOver() {
+			break
+		}
+		if r.badMoves >= r.config.IllegalMoves.maxBadMoves() {
 			r.emitRedLogf("Game over: too many illegal moves!")
 			break
 		}
@@ -81,7 +191,12 @@ func (r *runner) Run() (out []simstep.Action) {
 			r.emitRedLogf("Game over: round lasted for too long!")
 			break
 		}
-		if r.state.Round > r.config.Rounds {
+		if r.config.Endless {
+			if r.state.Round > endlessRoundsLimit {
+				r.emitRedLogf("Game over: reached the dungeon bottom!")
+				break
+			}
+		} else if r.state.Round > r.config.Rounds {
 			r.victory()
 			break
 		}
@@ -90,21 +205,70 @@ func (r *runner) Run() (out []simstep.Action) {
 			break
 		}
 	}
+	if r.puzzle != nil {
+		r.judgePuzzle()
+	}
+	r.applyScoreMultiplier()
+	if r.campaign != nil {
+		r.finishChapter()
+	}
+	if r.config.Endless {
+		r.finishEndless()
+	}
 	return r.out
 }
 
+// validate reports the settings that make the game unplayable.
+func (config *Config) validate() error {
+	if config.Endless {
+		if err := config.Scaling.validate(); err != nil {
+			return fmt.Errorf("scaling: %v", err)
+		}
+	}
+	return nil
+}
+
 func (r *runner) victory() {
 	r.out = append(r.out, simstep.Victory{})
 
 	bonus := r.state.Avatar.HP
+	r.state.Score += bonus
 	r.out = append(r.out, simstep.UpdateScore{Delta: bonus})
 	r.emitGreenLogf("Got %d survival bonus points", bonus)
 }
 
 func (r *runner) initWorld() {
-	r.state.Creep = newCreep(r.peekCreep(1))
-	r.state.NextCreep = r.peekCreep(2)
 	r.initDeck()
+	r.applyClass(r.selectClass())
+	if r.config.Draft {
+		r.runDraft()
+	}
+	r.modifyDeck()
+	if r.config.MapMode && !r.config.Endless {
+		r.dungeon = newDungeonMap(r.rand, r.config.Rounds)
+		r.modifyDungeon()
+		r.state.Creep = r.newCreep(r.dungeon.current(1).creep, 1)
+	} else {
+		r.state.Creep = r.newCreep(r.peekCreep(1), 1)
+	}
+	r.onRoundStart()
+	r.initCreepIntent()
+	r.emitCreepIntent()
+	r.state.NextCreep = r.peekCreep(2)
+	// The UI assumes the classic mode creeps by default.
+	if r.master != nil && r.master.ChooseCreep != nil || r.campaign != nil {
+		r.out = append(r.out, simstep.SetCreep{
+			Name: r.state.Creep.Type.String(),
+			HP:   r.state.Creep.HP,
+		})
+		r.emitCreepIntent()
+	}
+	if r.dungeon != nil || r.master != nil || r.campaign != nil {
+		r.out = append(r.out, simstep.SetNextCreep{
+			Name: r.state.NextCreep.String(),
+			HP:   r.creepStats(r.state.NextCreep, 2).MaxHP,
+		})
+	}
 }
 
 func (r *runner) initDeck() {
@@ -123,9 +287,13 @@ func (r *runner) initDeck() {
 			Type:      typ,
 			CardStats: cardStats,
 		}
-		switch typ {
-		case game.CardAttack, game.CardMagicArrow, game.CardRest, game.CardRetreat:
+		switch {
+		case typ == game.CardAttack, typ == game.CardMagicArrow, typ == game.CardRest, typ == game.CardRetreat:
 			card.Count = -1
+		case gamedata.IsSignatureCard(typ):
+			// Class cards are never given as rewards.
+		case !r.isAvailableCard(typ):
+			// Disabled cards are never given as rewards.
 		default:
 			r.peekableCards = append(r.peekableCards, typ)
 		}
@@ -133,20 +301,90 @@ func (r *runner) initDeck() {
 	}
 }
 
-func (r *runner) peekCard() game.CardType {
-	return r.peekableCards[r.rand.Intn(len(r.peekableCards))]
+// isAvailableCard reports whether the card can be obtained in this run,
+// either as a reward or from the draft.
+func (r *runner) isAvailableCard(typ game.CardType) bool {
+	switch {
+	case gamedata.IsExpansionCard(typ) && !r.config.ExpansionCards:
+		// Expansion cards are opt-in to keep the classic rewards intact.
+		return false
+	case r.campaign != nil && !r.campaign.cards[typ]:
+		// Locked campaign cards can't be obtained.
+		return false
+	default:
+		return true
+	}
+}
+
+// peekCard returns a random reward card.
+// It reports false if there are no reward cards,
+// for example, when all of them are locked in the campaign.
+func (r *runner) peekCard() (game.CardType, bool) {
+	if len(r.peekableCards) == 0 {
+		return 0, false
+	}
+	return r.peekableCards[r.rand.Intn(len(r.peekableCards))], true
 }
 
 func (r *runner) peekCreep(round int) game.CreepType {
+	if len(r.state.Forecast) != 0 {
+		typ := r.state.Forecast[0]
+		r.state.Forecast = r.state.Forecast[1:]
+		return typ
+	}
+	typ := r.selectCreep(round)
+	if r.dungeon != nil {
+		// Map creeps are modified when the map is generated.
+		return typ
+	}
+	return r.modifyCreepType(round, typ)
+}
+
+// revealCreeps extends the forecast up to n creeps after the NextCreep.
+// It reports whether the upcoming creeps can be predicted.
+func (r *runner) revealCreeps(n int) bool {
+	if r.dungeon != nil || r.master != nil && r.master.ChooseCreep != nil {
+		// These creeps are not selected until the round starts.
+		return false
+	}
+	for len(r.state.Forecast) < n {
+		round := r.state.Round + 2 + len(r.state.Forecast)
+		typ := r.modifyCreepType(round, r.selectCreep(round))
+		if typ == game.CreepNone {
+			break
+		}
+		r.state.Forecast = append(r.state.Forecast, typ)
+	}
+	return true
+}
+
+func (r *runner) selectCreep(round int) game.CreepType {
+	if r.config.Endless {
+		return r.peekEndlessCreep(round)
+	}
+
 	// This handles the next creep for the last round.
 	if round > r.config.Rounds {
 		return game.CreepNone
 	}
 
+	if r.puzzle != nil {
+		return r.puzzle.creeps[round-1]
+	}
+	if r.campaign != nil {
+		return r.peekCampaignCreep(round)
+	}
+	if r.dungeon != nil {
+		return r.choosePath()
+	}
+
 	// Dragon is always encountered at the last round.
 	if round == r.config.Rounds {
 		return game.CreepDragon
 	}
+	if r.master != nil && r.master.ChooseCreep != nil {
+		return r.masterCreep(round)
+	}
 	// Cheepy is always encountered at the first round.
 	if round == 1 {
 		return game.CreepCheepy
@@ -156,41 +394,116 @@ func (r *runner) peekCreep(round int) game.CreepType {
 		return game.CreepImp
 	}
 
+	return r.rollCreep(round)
+}
+
+func (r *runner) rollCreep(round int) game.CreepType {
 	roll := r.rand.Intn(99)
 
 	// First 5 rounds can't have high-tier enemies.
-	if r.state.Round <= 5 {
+	// Note that creeps are selected one round ahead.
+	if round-1 <= 5 {
 		switch {
 		case roll >= 90: // 10%
 			return game.CreepFairy
-		case roll >= 50: // 40%
+		case roll >= 85: // 5%
+			return game.CreepGhost
+		case roll >= 75: // 10%
+			return game.CreepSlime
+		case roll >= 40: // 35%
 			return game.CreepLion
-		case roll >= 30: // 20%
+		case roll >= 25: // 15%
 			return game.CreepImp
-		default: // 30%
+		default: // 25%
 			return game.CreepCheepy
 		}
 	}
 
 	switch {
-	case roll >= 70: // 30%
+	case roll >= 85: // 15%
 		return game.CreepMummy
-	case roll >= 50: // 20%
+	case roll >= 75: // 10%
+		return game.CreepTroll
+	case roll >= 65: // 10%
+		return game.CreepGolem
+	case roll >= 55: // 10%
+		return game.CreepVampire
+	case roll >= 40: // 15%
 		return game.CreepFairy
-	case roll >= 30: // 20%
+	case roll >= 35: // 5%
+		return game.CreepGhost
+	case roll >= 30: // 5%
+		return game.CreepSlime
+	case roll >= 20: // 10%
 		return game.CreepLion
-	case roll >= 10: // 20%
+	case roll >= 10: // 10%
 		return game.CreepImp
 	default: // 10%
 		return game.CreepCheepy
 	}
 }
 
-func (r *runner) runCreepAction(parried bool) {
+// runCreepAction performs the creep intent.
+// It returns true if creep has fled.
+func (r *runner) runCreepAction(parried bool) bool {
+	creep := &r.state.Creep
+
+	switch creep.Intent {
+	case game.IntentAttack:
+		r.creepAttack(parried, 1)
+
+	case game.IntentHeavyAttack:
+		r.emitRedLogf("%s unleashes a heavy attack", creep.Type.String())
+		r.creepAttack(parried, 2)
+
+	case game.IntentCharge:
+		r.emitLogf("%s is charging a heavy attack", creep.Type.String())
+
+	case game.IntentSummon:
+		creep.Minions++
+		r.emitRedLogf("%s summons a minion", creep.Type.String())
+
+	case game.IntentHeal:
+		boss, _ := gamedata.GetBossStats(creep.Type)
+		healed := calculateHealed(r.rangeRand(boss.HealPower), creep.HP, creep.MaxHP)
+		creep.HP += healed
+		r.out = append(r.out, simstep.UpdateCreepHP{Delta: healed})
+		r.emitRedLogf("%s recovers %d HP", creep.Type.String(), healed)
+
+	case game.IntentDefend:
+		r.emitLogf("%s is defending", creep.Type.String())
+
+	case game.IntentFlee:
+		return true
+
+	case game.IntentCast:
+		r.creepCast(parried)
+	}
+
+	switch creep.Intent {
+	case game.IntentAttack, game.IntentHeavyAttack, game.IntentCast:
+	default:
+		if parried {
+			r.emitRedLogf("Tried to parry, but the enemy was not attacking")
+		}
+	}
+	if creep.HP <= 0 {
+		return false
+	}
+
+	r.runMinionsAction()
+	r.setCreepIntent(r.nextCreepIntent())
+	return false
+}
+
+func (r *runner) creepAttack(parried bool, multiplier int) {
 	creep := &r.state.Creep
 	avatar := &r.state.Avatar
 
-	damageRoll := r.rangeRand(creep.Damage)
+	damageRoll := r.rangeRand(creep.Damage) * multiplier
+	if creep.Enraged {
+		damageRoll = damageRoll * 3 / 2
+	}
 	if parried {
 		if !creep.Traits.Has(game.TraitRanged) {
 			creep.HP -= damageRoll
@@ -201,104 +514,169 @@ func (r *runner) runCreepAction(parried bool) {
 		r.emitRedLogf("Failed to parry a ranged attack")
 	}
 
+	switch r.rollHit(creep.CombatStats, avatar.CombatStats) {
+	case hitMiss:
+		r.emitMissLogf("%s missed!", creep.Type.String())
+		return
+	case hitCritical:
+		damageRoll = criticalDamage(creep.CombatStats, damageRoll)
+		r.emitCritLogf("%s lands a critical hit!", creep.Type.String())
+	}
+
+	damageRoll = r.avatarDamage(damageRoll)
 	avatar.HP -= damageRoll
 	r.out = append(r.out, simstep.UpdateHP{Delta: -damageRoll})
 	r.emitRedLogf("%s deals %d damage", creep.Type.String(), damageRoll)
 }
 
-func (r *runner) runAvatarAction(cardType game.CardType, card game.CardStats) bool {
+func (r *runner) creepCast(parried bool) {
 	creep := &r.state.Creep
 	avatar := &r.state.Avatar
 
-	cardCount := r.state.Deck[cardType].Count
-	if cardCount == 0 {
-		r.emitRedLogf("Tried to use unavailable card %s", cardType.String())
-		r.badMoves++
-		return false
+	if parried {
+		r.emitRedLogf("Failed to parry a spell")
+	}
+	damageRoll := r.rangeRand(creep.Damage)
+	if creep.Enraged {
+		damageRoll = damageRoll * 3 / 2
+	}
+	damageRoll = r.avatarDamage(damageRoll)
+	avatar.HP -= damageRoll
+	r.out = append(r.out, simstep.UpdateHP{Delta: -damageRoll})
+	r.emitRedLogf("%s casts a spell that deals %d damage", creep.Type.String(), damageRoll)
+}
+
+func (r *runner) runMinionsAction() {
+	creep := &r.state.Creep
+	avatar := &r.state.Avatar
+
+	if creep.Minions == 0 {
+		return
+	}
+	boss, _ := gamedata.GetBossStats(creep.Type)
+	damage := 0
+	for i := 0; i < creep.Minions; i++ {
+		damage += r.rangeRand(boss.MinionDamage)
+	}
+	damage = r.avatarDamage(damage)
+	avatar.HP -= damage
+	r.out = append(r.out, simstep.UpdateHP{Delta: -damage})
+	r.emitRedLogf("%d minions of %s deal %d damage", creep.Minions, creep.Type.String(), damage)
+}
+
+// avatarDamage applies the rules and the block to the damage taken by the avatar.
+func (r *runner) avatarDamage(damage int) int {
+	dmg := damageEvent{amount: damage}
+	r.onDamageTaken(&dmg)
+	if block := r.state.Avatar.Block; block > 0 && dmg.amount > 0 {
+		blocked := block
+		if blocked > dmg.amount {
+			blocked = dmg.amount
+		}
+		dmg.amount -= blocked
+		r.setAvatarBlock(0)
+		r.emitGreenLogf("Blocked %d damage", blocked)
+	}
+	return dmg.amount
+}
+
+func (r *runner) runAvatarAction(play *cardPlay) bool {
+	// The move is validated before any state change,
+	// so the illegal moves don't consume the cards.
+	if reason := r.validateMove(play.cardType); reason != moveOK {
+		substitute, ok := r.rejectMove(play.cardType, reason)
+		if !ok {
+			return false
+		}
+		play.cardType = substitute
+		play.card = r.state.Deck[substitute].CardStats
 	}
-	if cardCount != -1 {
+
+	avatar := &r.state.Avatar
+	cardType := play.cardType
+	card := play.card
+
+	if r.state.Deck[cardType].Count != -1 {
 		r.out = append(r.out, simstep.ChangeCardCount{
 			Name:  cardType.String(),
 			Delta: -1,
 		})
 		changeDeckCardCount(r.state.Deck, cardType, -1)
 	}
-
 	if card.MP != 0 {
-		if avatar.MP < card.MP {
-			r.emitRedLogf("Not enough mana to use %s", cardType.String())
-			r.badMoves++
-			return false
-		}
 		avatar.MP -= card.MP
 		r.out = append(r.out, simstep.UpdateMP{Delta: -card.MP})
 	}
 
-	switch cardType {
-	case game.CardRetreat:
-		r.emitLogf("Trying to retreat...")
+	if r.config.Combos {
+		play.combo = r.matchCombo(cardType)
+		if play.combo != nil {
+			r.emitGreenLogf("%s combo!", play.combo.Name)
+		}
+	}
+	r.onCardPlayed(play)
+	r.rememberCard(cardType)
+	return true
+}
 
-	case game.CardAttack, game.CardPowerAttack:
-		damageRoll := r.rangeRand(card.Power)
-		creep.HP -= damageRoll
-		r.out = append(r.out, simstep.UpdateCreepHP{Delta: -damageRoll})
-		r.emitLogf("Your %s deals %d damage", cardType.String(), damageRoll)
+func (r *runner) damageCreep(cardType game.CardType, element game.Element, damage int) int {
+	creep := &r.state.Creep
 
-	case game.CardStun:
-		stunRoll := r.rangeRand(card.Power)
-		creep.Stun = stunRoll
-		r.emitLogf("%s is stunned for %d turns", creep.Type.String(), stunRoll)
+	dmg := damageEvent{cardType: cardType, element: element, amount: damage}
+	r.onDamageDealt(&dmg)
+	damage = creep.ResistDamage(element, dmg.amount)
 
-	case game.CardMagicArrow:
-		if creep.Traits.Has(game.TraitMagicImmunity) {
-			r.emitRedLogf("%s failed: is immune to magic", cardType.String())
-			break
-		}
-		damageRoll := r.rangeRand(card.Power)
-		creep.HP -= damageRoll
-		r.out = append(r.out, simstep.UpdateCreepHP{Delta: -damageRoll})
-		r.emitLogf("Your %s deals %d damage", cardType.String(), damageRoll)
-
-	case game.CardFirebolt:
-		if creep.Traits.Has(game.TraitMagicImmunity) {
-			r.emitRedLogf("%s failed: is immune to magic", cardType.String())
-			break
-		}
-		damageRoll := r.rangeRand(card.Power)
-		if creep.Traits.Has(game.TraitWeakToFire) {
-			damageRoll *= 2
-		}
-		creep.HP -= damageRoll
-		r.out = append(r.out, simstep.UpdateCreepHP{Delta: -damageRoll})
-		r.emitLogf("Your %s deals %d damage", cardType.String(), damageRoll)
-
-	case game.CardRest, game.CardHeal:
-		r.avatarHeal(cardType, card)
+	if creep.Intent == game.IntentDefend && !creep.IsStunned() {
+		damage /= 2
+		r.emitLogf("%s blocks half of the damage", creep.Type.String())
 	}
-
-	return true
+	creep.HP -= damage
+	r.out = append(r.out, simstep.UpdateCreepHP{Delta: -damage})
+	r.emitLogf("Your %s deals %d damage", cardType.String(), damage)
+	return damage
 }
 
-func (r *runner) avatarHeal(cardType game.CardType, card game.CardStats) {
+func (r *runner) avatarHeal(source string, amount int) {
 	avatar := &r.state.Avatar
 
-	roll := r.rangeRand(card.Power)
-	healed := calculateHealed(roll, avatar.HP, r.config.AvatarHP)
+	healed := calculateHealed(amount, avatar.HP, avatar.MaxHP)
 	avatar.HP += healed
 	r.out = append(r.out, simstep.UpdateHP{Delta: healed})
-	r.emitGreenLogf("Got %d HP from %s", healed, cardType.String())
+	r.emitGreenLogf("Got %d HP from %s", healed, source)
 }
 
 func (r *runner) creepDefeated() {
 	creep := &r.state.Creep
 
+	defeat := defeatEvent{creep: creep, cardsReward: creep.CardsReward}
+	r.onCreepDefeated(&defeat)
+	if defeat.canceled {
+		return
+	}
+
+	if r.puzzle != nil {
+		r.puzzle.creepDefeated(creep.Type, r.state.Turn)
+	}
+	if r.campaign != nil && r.state.Round == r.config.Rounds {
+		// Chapter is cleared only if its boss is defeated.
+		r.campaign.cleared = true
+	}
+
 	r.state.Score += creep.ScoreReward
 	r.emitGreenLogf("%s is defeated! %d score points received",
 		creep.Type.String(), creep.ScoreReward)
 	r.out = append(r.out, simstep.UpdateScore{Delta: creep.ScoreReward})
 
-	for i := 0; i < creep.CardsReward; i++ {
-		rewardCardType := r.peekCard()
+	if r.config.Leveling {
+		// Creeps give as much experience as score points.
+		r.gainXP(creep.ScoreReward)
+	}
+
+	for i := 0; i < defeat.cardsReward; i++ {
+		rewardCardType, ok := r.peekCard()
+		if !ok {
+			break
+		}
 		r.emitGreenLogf("Collected %s card", rewardCardType.String())
 		r.out = append(r.out, simstep.ChangeCardCount{
 			Name:  rewardCardType.String(),
@@ -307,6 +685,10 @@ func (r *runner) creepDefeated() {
 		changeDeckCardCount(r.state.Deck, rewardCardType, 1)
 	}
 
+	if r.config.Potions {
+		r.dropPotions(creep.Type)
+	}
+
 	r.nextRound()
 }
 
@@ -315,95 +697,212 @@ func (r *runner) runTurn() bool {
 	defer r.endTurn()
 
 	creep := &r.state.Creep
-	avatar := &r.state.Avatar
 
-	cardType := r.chooseCard(cloneState(r.state))
-	card := gamedata.GetCardStats(cardType)
-	cardIsPlayed := r.runAvatarAction(cardType, card)
+	r.onTurnStart()
+	cardType := potionTurn
+	if !r.usePotion() {
+		cardType = r.tactic.ChooseCard(cloneState(r.state))
+	}
+	play := &cardPlay{
+		cardType: cardType,
+		card:     r.state.Deck[cardType].CardStats,
+	}
+	cardIsPlayed := cardType != potionTurn && r.runAvatarAction(play)
 
 	if creep.HP <= 0 {
 		r.creepDefeated()
-		return false
+		// The avatar can be hurt during its own move, see thornsTrait.
+		return r.checkAvatarDefeat()
 	}
 
-	retreatedBeforeAttacked := cardType == game.CardRetreat &&
-		creep.Traits.Has(game.TraitSlow)
-	skipsAttack := creep.IsFull() &&
-		creep.Traits.Has(game.TraitCoward)
+	turn := &creepTurn{play: play, played: cardIsPlayed, skip: play.evade}
+	r.onCreepTurn(turn)
 	stunned := creep.IsStunned()
-	if !stunned && !retreatedBeforeAttacked && !skipsAttack {
-		parried := cardType == game.CardParry
-		r.runCreepAction(parried)
+	fled := false
+	if !stunned && !turn.skip {
+		// Illegal moves have no effect, so the rejected Parry doesn't parry.
+		parried := cardIsPlayed && gamedata.HasEffect(play.cardType, gamedata.EffectParry)
+		fled = r.runCreepAction(parried)
 		if parried && creep.HP <= 0 {
 			r.creepDefeated()
-			return false
+			return r.checkAvatarDefeat()
 		}
 	}
-	if skipsAttack && cardType == game.CardParry && cardIsPlayed {
+	if turn.skip && gamedata.HasEffect(play.cardType, gamedata.EffectParry) && cardIsPlayed {
 		r.emitRedLogf("Tried to parry, but the enemy was not attacking")
 	}
 
 	if creep.Stun > 0 {
 		creep.Stun--
 	}
+	if creep.Poison > 0 && creep.HP > 0 {
+		r.damageCreep(game.CardPoison, game.ElementPoison, gamedata.PoisonDamage)
+		r.setCreepPoison(creep.Poison - 1)
+	}
 
-	if avatar.HP <= 0 {
-		r.out = append(r.out, simstep.Defeat{})
-		r.emitRedLogf("Game over: avatar has been defeated!")
+	if r.checkAvatarDefeat() {
 		return true
 	}
+	if creep.HP <= 0 {
+		r.creepDefeated()
+		return false
+	}
 
-	if cardType == game.CardRetreat {
-		r.emitLogf("Retreated from %s!", creep.Type.String())
+	if fled {
+		r.emitRedLogf("%s ran away!", creep.Type.String())
+		r.nextRound()
+		return false
+	}
+
+	if cardIsPlayed && gamedata.HasEffect(play.cardType, gamedata.EffectRetreat) && r.retreat(play) {
 		r.nextRound()
 	}
 
 	return false
 }
 
+// checkAvatarDefeat reports whether the game is over due to the avatar death.
+func (r *runner) checkAvatarDefeat() bool {
+	if r.state.Avatar.HP > 0 {
+		return false
+	}
+	r.out = append(r.out, simstep.Defeat{})
+	r.emitRedLogf("Game over: avatar has been defeated!")
+	return true
+}
+
 func (r *runner) nextRound() {
 	r.state.Round++
 	r.state.RoundTurn = 0
+	r.state.LastCards = nil
+
+	if r.dungeon != nil && r.state.Round <= r.config.Rounds {
+		r.dungeon.pos = r.dungeon.next
+		switch kind := r.dungeon.current(r.state.Round).Kind; kind {
+		case game.PathShop, game.PathRest:
+			r.visitSite(kind)
+			return
+		}
+	}
 
-	r.state.Creep = newCreep(r.state.NextCreep)
+	r.state.Creep = r.newCreep(r.state.NextCreep, r.state.Round)
+	r.onRoundStart()
+	r.initCreepIntent()
 	r.state.NextCreep = r.peekCreep(r.state.Round + 1)
 	r.out = append(r.out, simstep.SetCreep{
 		Name: r.state.Creep.Type.String(),
 		HP:   r.state.Creep.HP,
 	})
+	r.emitCreepIntent()
 	r.out = append(r.out, simstep.SetNextCreep{
 		Name: r.state.NextCreep.String(),
-		HP:   gamedata.GetCreepStats(r.state.NextCreep).MaxHP,
+		HP:   r.creepStats(r.state.NextCreep, r.state.Round+1).MaxHP,
 	})
 	r.out = append(r.out, simstep.NextRound{})
 }
 
-func (r *runner) rangeRand(rng game.IntRange) int {
-	if rng.IsZero() {
-		return 0
+// choosePath asks the tactic to select one of the current map node
+// branches and returns a creep type that will be encountered there.
+func (r *runner) choosePath() game.CreepType {
+	round := r.state.Round
+	node := r.dungeon.current(round)
+
+	paths := make([]game.PathOption, len(node.next))
+	names := make([]string, len(node.next))
+	for i, index := range node.next {
+		path := r.dungeon.node(round+1, index).PathOption
+		paths[i] = path
+		names[i] = path.Kind.String()
+		if path.Kind == game.PathCreep {
+			names[i] += fmt.Sprintf(" (tier %d)", path.Tier)
+		}
+	}
+	r.out = append(r.out, simstep.ShowPaths{Options: names})
+
+	// The previous preview is the current creep now,
+	// the next one is not known until the path is selected.
+	r.state.NextCreep = game.CreepNone
+	selected := 0
+	if r.tactic.ChoosePath != nil {
+		selected = r.tactic.ChoosePath(cloneState(r.state), paths)
+	}
+	if selected < 0 || selected >= len(node.next) {
+		r.emitRedLogf("Tried to select unavailable path %d", selected)
+		r.badMoves++
+		selected = 0
 	}
-	v := r.rand.Intn(rng.High() - rng.Low() + 1)
-	return v + rng.Low()
+	r.out = append(r.out, simstep.SelectPath{Index: selected})
+
+	r.dungeon.next = node.next[selected]
+	return r.dungeon.node(round+1, r.dungeon.next).creep
 }
 
-func (r *runner) beginTurn() {
-	r.emitLogf("--- Turn %d ---", r.state.Turn)
+// visitSite handles the map mode rounds that have no creep encounter.
+func (r *runner) visitSite(kind game.PathKind) {
+	r.state.Creep = r.newCreep(game.CreepNone, r.state.Round)
+	r.out = append(r.out, simstep.SetCreep{
+		Name: r.state.Creep.Type.String(),
+		HP:   r.state.Creep.HP,
+	})
+	r.out = append(r.out, simstep.NextRound{})
+
+	switch kind {
+	case game.PathShop:
+		r.visitShop()
+	case game.PathRest:
+		r.visitRestSite()
+	}
+
+	r.state.NextCreep = r.peekCreep(r.state.Round + 1)
+	r.out = append(r.out, simstep.SetNextCreep{
+		Name: r.state.NextCreep.String(),
+		HP:   r.creepStats(r.state.NextCreep, r.state.Round+1).MaxHP,
+	})
+	r.nextRound()
 }
 
-func (r *runner) endTurn() {
-	r.state.Turn++
-	r.state.RoundTurn++
-	r.out = append(r.out, simstep.Wait{})
+func (r *runner) visitShop() {
+	price := gamedata.ShopCardPrice
+	if r.state.Score < price {
+		r.emitRedLogf("Not enough score points to buy a card in the shop")
+		return
+	}
+	cardType, ok := r.peekCard()
+	if !ok {
+		r.emitRedLogf("The shop has no cards to sell")
+		return
+	}
+
+	r.state.Score -= price
+	r.out = append(r.out, simstep.UpdateScore{Delta: -price})
+	r.emitGreenLogf("Bought %s card for %d score points", cardType.String(), price)
+	r.out = append(r.out, simstep.ChangeCardCount{
+		Name:  cardType.String(),
+		Delta: 1,
+	})
+	changeDeckCardCount(r.state.Deck, cardType, 1)
 }
 
-func (r *runner) emitLogf(format string, args ...interface{}) {
-	r.out = append(r.out, simstep.Log{Message: fmt.Sprintf(format, args...)})
+func (r *runner) visitRestSite() {
+	avatar := &r.state.Avatar
+
+	healed := calculateHealed(r.rangeRand(gamedata.RestSiteHP), avatar.HP, avatar.MaxHP)
+	avatar.HP += healed
+	r.out = append(r.out, simstep.UpdateHP{Delta: healed})
+
+	restored := calculateHealed(r.rangeRand(gamedata.RestSiteMP), avatar.MP, avatar.MaxMP)
+	avatar.MP += restored
+	r.out = append(r.out, simstep.UpdateMP{Delta: restored})
+
+	r.emitGreenLogf("Got %d HP and %d MP at the rest site", healed, restored)
 }
 
-func (r *runner) emitRedLogf(format string, args ...interface{}) {
-	r.out = append(r.out, simstep.RedLog{Message: fmt.Sprintf(format, args...)})
+func (r *runner) beginTurn() {
+	r.emitLogf("--- Turn %d ---", r.state.Turn)
 }
 
-func (r *runner) emitGreenLogf(format string, args ...interface{}) {
-	r.out = append(r.out, simstep.GreenLog{Message: fmt.Sprintf(format, args...)})
+func (r *runner) endTurn() {
+	r.state.Turn++
+	r.state.RoundTurn++
+	r.out = append(r.out, simstep.Wait{})
 }
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
 )
 
 func TestCalculateHealed(t *testing.T) {
@@ -67,3 +68,61 @@ func TestRun(t *testing.T) {
 		}
 	}
 }
+
+// testRunnerOption customizes the runner created by newTestRunner.
+type testRunnerOption func(*testRunnerParams)
+
+type testRunnerParams struct {
+	tactic *Tactic
+	creep  game.CreepType
+}
+
+func withTactic(tactic *Tactic) testRunnerOption {
+	return func(p *testRunnerParams) { p.tactic = tactic }
+}
+
+func withChooseCard(chooseCard func(game.State) game.CardType) testRunnerOption {
+	return func(p *testRunnerParams) { p.tactic = &Tactic{ChooseCard: chooseCard} }
+}
+
+// withCard makes the tactic play the same card every turn.
+func withCard(typ game.CardType) testRunnerOption {
+	return withChooseCard(func(game.State) game.CardType { return typ })
+}
+
+func withCreep(typ game.CreepType) testRunnerOption {
+	return func(p *testRunnerParams) { p.creep = typ }
+}
+
+// newTestRunner returns a runner with an initialized world that fights the Lion,
+// unless the options say otherwise.
+// Zero config stats are replaced by the classic 40 HP, 20 MP and 10 rounds.
+func newTestRunner(t *testing.T, config *Config, opts ...testRunnerOption) *runner {
+	t.Helper()
+	params := testRunnerParams{tactic: &Tactic{}, creep: game.CreepLion}
+	for _, opt := range opts {
+		opt(&params)
+	}
+	if config.AvatarHP == 0 {
+		config.AvatarHP = 40
+	}
+	if config.AvatarMP == 0 {
+		config.AvatarMP = 20
+	}
+	if config.Rounds == 0 {
+		config.Rounds = 10
+	}
+	r := newRunner(config, params.tactic)
+	r.initWorld()
+	r.state.Creep = newCreep(params.creep, r.creepStats(params.creep, 1))
+	return r
+}
+
+// checkNoPanic fails the test if the simulation recovered from a panic.
+func checkNoPanic(t *testing.T, actions []simstep.Action) {
+	for _, a := range actions {
+		if a, ok := a.(simstep.RedLog); ok && strings.HasPrefix(a.Message, "Panic: ") {
+			t.Fatalf("simulation panicked: %s", a.Message)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package sim
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// simBase is embedded by the game and duel runners.
+// It holds the produced actions and the source of random rolls.
+type simBase struct {
+	out  []simstep.Action
+	rand roller
+}
+
+func (b *simBase) rangeRand(rng game.IntRange) int {
+	if rng.IsZero() {
+		return 0
+	}
+	v := b.rand.Intn(rng.High() - rng.Low() + 1)
+	return v + rng.Low()
+}
+
+// effectAmount returns a fixed effect amount or rolls the card power.
+func (b *simBase) effectAmount(card game.CardStats, e gamedata.CardEffect) int {
+	if e.Amount != 0 {
+		return e.Amount
+	}
+	return b.rangeRand(card.Power)
+}
+
+func (b *simBase) emitLogf(format string, args ...interface{}) {
+	b.out = append(b.out, simstep.Log{Message: fmt.Sprintf(format, args...)})
+}
+
+func (b *simBase) emitRedLogf(format string, args ...interface{}) {
+	b.out = append(b.out, simstep.RedLog{Message: fmt.Sprintf(format, args...)})
+}
+
+func (b *simBase) emitGreenLogf(format string, args ...interface{}) {
+	b.out = append(b.out, simstep.GreenLog{Message: fmt.Sprintf(format, args...)})
+}
+
+func (b *simBase) emitMissLogf(format string, args ...interface{}) {
+	b.out = append(b.out, simstep.MissLog{Message: fmt.Sprintf(format, args...)})
+}
+
+func (b *simBase) emitCritLogf(format string, args ...interface{}) {
+	b.out = append(b.out, simstep.CritLog{Message: fmt.Sprintf(format, args...)})
+}
+
+// recoverPanic turns a simulation panic into a game log message.
+// It should be deferred by the Run methods: defer recoverPanic(&out).
+func recoverPanic(out *[]simstep.Action) {
+	rv := recover()
+	if rv == nil {
+		return // OK
+	}
+	*out = append(*out, simstep.RedLog{Message: "Panic: " + fmt.Sprint(rv)})
+	// Print stack trace to the JS console.
+	println(string(debug.Stack()))
+}
@@ -0,0 +1,131 @@
+package sim
+
+import (
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
+)
+
+// traitRules implements creep traits.
+// TraitWeakToFire and TraitIncorporeal are expressed with the creep resistances instead.
+var traitRules = map[game.CreepTrait]rule{
+	game.TraitCoward:        cowardTrait{},
+	game.TraitMagicImmunity: magicImmunityTrait{},
+	game.TraitSlow:          slowTrait{},
+	game.TraitRegeneration:  regenerationTrait{},
+	game.TraitThorns:        thornsTrait{},
+	game.TraitLifesteal:     lifestealTrait{},
+	game.TraitSplit:         splitTrait{},
+	game.TraitFast:          fastTrait{},
+}
+
+// cowardTrait creeps don't attack until they're attacked.
+type cowardTrait struct{ ruleBase }
+
+func (cowardTrait) OnCreepTurn(r *runner, turn *creepTurn) {
+	// Coward creeps don't notice the backstab.
+	backstab := turn.play.cardType == game.CardBackstab && turn.played
+	if r.state.Creep.IsFull() || backstab {
+		turn.skip = true
+	}
+}
+
+// magicImmunityTrait creeps can't be affected by the offensive spells.
+type magicImmunityTrait struct{ ruleBase }
+
+func (magicImmunityTrait) OnCardPlayed(r *runner, play *cardPlay) {
+	if play.card.IsMagic && play.card.IsOffensive {
+		r.emitRedLogf("%s failed: is immune to magic", play.cardType.String())
+		play.canceled = true
+	}
+}
+
+// slowTrait creeps can't attack the retreating avatar.
+type slowTrait struct{ ruleBase }
+
+func (slowTrait) OnCreepTurn(r *runner, turn *creepTurn) {
+	if turn.played && gamedata.HasEffect(turn.play.cardType, gamedata.EffectRetreat) {
+		turn.skip = true
+	}
+}
+
+// regenerationTrait creeps recover HP at the start of every turn.
+type regenerationTrait struct{ ruleBase }
+
+func (regenerationTrait) OnTurnStart(r *runner) {
+	creep := &r.state.Creep
+	healed := calculateHealed(gamedata.RegenerationHP, creep.HP, creep.MaxHP)
+	if healed <= 0 {
+		return
+	}
+	creep.HP += healed
+	r.out = append(r.out, simstep.UpdateCreepHP{Delta: healed})
+	r.emitRedLogf("%s regenerates %d HP", creep.Type.String(), healed)
+}
+
+// thornsTrait creeps reflect a part of the physical damage back to the avatar.
+type thornsTrait struct{ ruleBase }
+
+func (thornsTrait) OnDamageDealt(r *runner, dmg *damageEvent) {
+	if dmg.element != game.ElementPhysical {
+		return
+	}
+	reflected := r.avatarDamage(dmg.amount * gamedata.ThornsPercent / 100)
+	if reflected == 0 {
+		return
+	}
+	r.state.Avatar.HP -= reflected
+	r.out = append(r.out, simstep.UpdateHP{Delta: -reflected})
+	r.emitRedLogf("%s reflects %d damage back to you", r.state.Creep.Type.String(), reflected)
+}
+
+// lifestealTrait creeps recover a part of the damage they deal as HP.
+type lifestealTrait struct{ ruleBase }
+
+func (lifestealTrait) OnDamageTaken(r *runner, dmg *damageEvent) {
+	creep := &r.state.Creep
+	healed := calculateHealed(dmg.amount*gamedata.LifestealPercent/100, creep.HP, creep.MaxHP)
+	if healed <= 0 {
+		return
+	}
+	creep.HP += healed
+	r.out = append(r.out, simstep.UpdateCreepHP{Delta: healed})
+	r.emitRedLogf("%s drains %d HP", creep.Type.String(), healed)
+}
+
+// splitTrait creeps come back with half of their MaxHP after the first defeat.
+// The split creep loses this trait.
+type splitTrait struct{ ruleBase }
+
+func (splitTrait) OnCreepDefeated(r *runner, defeat *defeatEvent) {
+	creep := defeat.creep
+	hp := creep.MaxHP / 2
+	r.out = append(r.out, simstep.UpdateCreepHP{Delta: hp - creep.HP})
+	creep.HP = hp
+
+	// Traits slice is shared with the creep stats table.
+	traits := make(game.CreepTraitList, 0, len(creep.Traits))
+	for _, trait := range creep.Traits {
+		if trait != game.TraitSplit {
+			traits = append(traits, trait)
+		}
+	}
+	creep.Traits = traits
+	defeat.canceled = true
+	r.emitRedLogf("%s splits into a smaller %s!", creep.Type.String(), creep.Type.String())
+}
+
+// fastTrait creeps turn their attack into a heavy one when the avatar retreats.
+// The lower retreat chance is handled by the runner.retreat.
+type fastTrait struct{ ruleBase }
+
+func (fastTrait) OnCreepTurn(r *runner, turn *creepTurn) {
+	creep := &r.state.Creep
+	if !r.config.RiskyRetreat || turn.skip || creep.IsStunned() || creep.Intent != game.IntentAttack {
+		return
+	}
+	if turn.played && gamedata.HasEffect(turn.play.cardType, gamedata.EffectRetreat) {
+		r.emitRedLogf("%s rushes at you as you turn away", creep.Type.String())
+		r.setCreepIntent(game.IntentHeavyAttack)
+	}
+}
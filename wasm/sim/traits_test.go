@@ -0,0 +1,81 @@
+package sim
+
+import (
+	"testing"
+
+	"github.com/quasilyte/gophers-and-dragons/game"
+	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+)
+
+func TestCreepTraits(t *testing.T) {
+	t.Run("Regeneration", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCreep(game.CreepTroll))
+		r.state.Creep.HP = 10
+		r.onTurnStart()
+		if r.state.Creep.HP != 12 {
+			t.Errorf("have %d HP, want 12", r.state.Creep.HP)
+		}
+		r.state.Creep.HP = r.state.Creep.MaxHP
+		r.onTurnStart()
+		if r.state.Creep.HP != r.state.Creep.MaxHP {
+			t.Errorf("regenerated over MaxHP")
+		}
+	})
+
+	t.Run("Thorns", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCreep(game.CreepGolem))
+		r.damageCreep(game.CardAttack, game.ElementPhysical, 4)
+		if r.state.Avatar.HP != 38 {
+			t.Errorf("avatar: have %d HP, want 38", r.state.Avatar.HP)
+		}
+		if r.state.Creep.HP != 22-3 {
+			t.Errorf("creep: have %d HP, want %d", r.state.Creep.HP, 22-3)
+		}
+		r.damageCreep(game.CardFirebolt, game.ElementFire, 4)
+		if r.state.Avatar.HP != 38 {
+			t.Errorf("fire damage is reflected")
+		}
+	})
+
+	t.Run("Lifesteal", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCreep(game.CreepVampire))
+		r.state.Creep.HP = 5
+		r.avatarDamage(4)
+		if r.state.Creep.HP != 7 {
+			t.Errorf("have %d HP, want 7", r.state.Creep.HP)
+		}
+	})
+
+	t.Run("Split", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCreep(game.CreepSlime))
+		r.state.Creep.HP = -2
+		r.creepDefeated()
+		if r.state.Creep.HP != 4 || r.state.Score != 0 || r.state.Round != 1 {
+			t.Fatalf("slime is defeated instead of splitting")
+		}
+		if r.state.Creep.Traits.Has(game.TraitSplit) {
+			t.Fatalf("split slime still has the Split trait")
+		}
+		if !gamedata.GetCreepStats(game.CreepSlime).Traits.Has(game.TraitSplit) {
+			t.Fatalf("slime stats table is modified")
+		}
+		reward := r.state.Creep.ScoreReward
+		r.state.Creep.HP = 0
+		r.creepDefeated()
+		if r.state.Score != reward || r.state.Round != 2 {
+			t.Errorf("split slime is not defeated")
+		}
+	})
+
+	t.Run("Incorporeal", func(t *testing.T) {
+		r := newTestRunner(t, &Config{Seed: 1}, withCreep(game.CreepGhost))
+		r.damageCreep(game.CardAttack, game.ElementPhysical, 4)
+		if r.state.Creep.HP != 8 {
+			t.Errorf("physical attack: have %d HP, want 8", r.state.Creep.HP)
+		}
+		r.damageCreep(game.CardMagicArrow, game.ElementArcane, 3)
+		if r.state.Creep.HP != 5 {
+			t.Errorf("arcane attack: have %d HP, want 5", r.state.Creep.HP)
+		}
+	})
+}
@@ -2,11 +2,9 @@ package sim
 
 import (
 	"github.com/quasilyte/gophers-and-dragons/game"
-	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
 )
 
-func newCreep(typ game.CreepType) game.Creep {
-	stats := gamedata.GetCreepStats(typ)
+func newCreep(typ game.CreepType, stats game.CreepStats) game.Creep {
 	return game.Creep{
 		Type:       typ,
 		HP:         stats.MaxHP,
@@ -14,6 +12,17 @@ func newCreep(typ game.CreepType) game.Creep {
 	}
 }
 
+// appendTraits returns a new traits list with the extra traits added.
+// Traits slices are shared with the creep stats table, so they're never appended in place.
+func appendTraits(traits game.CreepTraitList, extra ...game.CreepTrait) game.CreepTraitList {
+	if len(extra) == 0 {
+		return traits
+	}
+	out := make(game.CreepTraitList, len(traits), len(traits)+len(extra))
+	copy(out, traits)
+	return append(out, extra...)
+}
+
 func changeDeckCardCount(deck map[game.CardType]game.Card, typ game.CardType, delta int) {
 	card := deck[typ]
 	card.Count += delta
@@ -31,7 +40,24 @@ func calculateHealed(roll, current, max int) int {
 
 func cloneState(st *game.State) game.State {
 	out := *st
+	// Creep stats slices and maps are shared with the gamedata tables.
+	out.Creep.Traits = append(game.CreepTraitList(nil), out.Creep.Traits...)
+	out.Creep.Resistances = cloneResistances(out.Creep.Resistances)
 	out.Deck = cloneDeck(out.Deck)
+	out.Forecast = append([]game.CreepType(nil), out.Forecast...)
+	out.LastCards = append([]game.CardType(nil), out.LastCards...)
+	out.Potions = append([]game.Potion(nil), out.Potions...)
+	return out
+}
+
+func cloneResistances(resistances game.Resistances) game.Resistances {
+	if resistances == nil {
+		return nil
+	}
+	out := make(game.Resistances, len(resistances))
+	for e, percent := range resistances {
+		out[e] = percent
+	}
 	return out
 }
 
@@ -42,3 +68,10 @@ func cloneDeck(deck map[game.CardType]game.Card) map[game.CardType]game.Card {
 	}
 	return out
 }
+
+// roller is a source of random rolls.
+// It's implemented by *rand.Rand and by scripted puzzle rolls.
+type roller interface {
+	Intn(n int) int
+	Shuffle(n int, swap func(i, j int))
+}
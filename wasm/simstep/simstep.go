@@ -110,3 +110,190 @@ type SetNextCreep struct {
 func (a SetNextCreep) Fields() []interface{} {
 	return []interface{}{"setNextCreep", a.Name, a.HP}
 }
+
+type ShowPaths struct {
+	Options []string
+}
+
+func (a ShowPaths) Fields() []interface{} {
+	options := make([]interface{}, len(a.Options))
+	for i, o := range a.Options {
+		options[i] = o
+	}
+	return []interface{}{"showPaths", options}
+}
+
+type SelectPath struct {
+	Index int
+}
+
+func (a SelectPath) Fields() []interface{} {
+	return []interface{}{"selectPath", a.Index}
+}
+
+type SetCreepIntent struct {
+	Name string
+}
+
+func (a SetCreepIntent) Fields() []interface{} {
+	return []interface{}{"setCreepIntent", a.Name}
+}
+
+// Meta is a replay metadata record.
+type Meta struct {
+	Key   string
+	Value string
+}
+
+func (a Meta) Fields() []interface{} {
+	return []interface{}{"meta", a.Key, a.Value}
+}
+
+type DropCard struct {
+	Name string
+}
+
+func (a DropCard) Fields() []interface{} {
+	return []interface{}{"dropCard", a.Name}
+}
+
+// SetCardCount sets the card count; -1 means "unlimited".
+type SetCardCount struct {
+	Name  string
+	Count int
+}
+
+func (a SetCardCount) Fields() []interface{} {
+	return []interface{}{"setCardCount", a.Name, a.Count}
+}
+
+type LevelUp struct {
+	Level int
+}
+
+func (a LevelUp) Fields() []interface{} {
+	return []interface{}{"levelUp", a.Level}
+}
+
+type UpdateDuelHP struct {
+	Side  int
+	Delta int
+}
+
+func (a UpdateDuelHP) Fields() []interface{} {
+	return []interface{}{"updateDuelHP", a.Side, a.Delta}
+}
+
+type UpdateDuelMP struct {
+	Side  int
+	Delta int
+}
+
+func (a UpdateDuelMP) Fields() []interface{} {
+	return []interface{}{"updateDuelMP", a.Side, a.Delta}
+}
+
+type ChangeDuelCardCount struct {
+	Side  int
+	Name  string
+	Delta int
+}
+
+func (a ChangeDuelCardCount) Fields() []interface{} {
+	return []interface{}{"changeDuelCardCount", a.Side, a.Name, a.Delta}
+}
+
+// DuelResult reports the duel winner side; -1 means a draw.
+type DuelResult struct {
+	Winner int
+}
+
+func (a DuelResult) Fields() []interface{} {
+	return []interface{}{"duelResult", a.Winner}
+}
+
+type PuzzleResult struct {
+	Solved bool
+}
+
+func (a PuzzleResult) Fields() []interface{} {
+	return []interface{}{"puzzleResult", a.Solved}
+}
+
+// EndlessResult reports the endless run result.
+// BestScore is the updated high-water mark that should be persisted.
+type EndlessResult struct {
+	Round     int
+	Score     int
+	BestScore int
+}
+
+func (a EndlessResult) Fields() []interface{} {
+	return []interface{}{"endlessResult", a.Round, a.Score, a.BestScore}
+}
+
+// SaveProgress carries the campaign progress that should be persisted.
+type SaveProgress struct {
+	Data string
+}
+
+func (a SaveProgress) Fields() []interface{} {
+	return []interface{}{"saveProgress", a.Data}
+}
+
+type MissLog struct {
+	Message string
+}
+
+func (a MissLog) Fields() []interface{} {
+	return []interface{}{"missLog", a.Message}
+}
+
+type CritLog struct {
+	Message string
+}
+
+func (a CritLog) Fields() []interface{} {
+	return []interface{}{"critLog", a.Message}
+}
+
+// SetStatus describes a status change of the "avatar" or "creep" target.
+// Zero value means that the status is removed.
+type SetStatus struct {
+	Target string
+	Name   string
+	Value  int
+}
+
+func (a SetStatus) Fields() []interface{} {
+	return []interface{}{"setStatus", a.Target, a.Name, a.Value}
+}
+
+// SetForecast lists the creeps that are encountered after the next creep.
+type SetForecast struct {
+	Names string
+}
+
+func (a SetForecast) Fields() []interface{} {
+	return []interface{}{"setForecast", a.Names}
+}
+
+// SetPotions lists the potions inside the avatar inventory.
+type SetPotions struct {
+	Names string
+}
+
+func (a SetPotions) Fields() []interface{} {
+	return []interface{}{"setPotions", a.Names}
+}
+
+// IllegalMove describes a rejected card move.
+// Reason is a short explanation, like "not enough mana".
+type IllegalMove struct {
+	Card   string
+	Reason string
+}
+
+func (a IllegalMove) Fields() []interface{} {
+	return []interface{}{"illegalMove", a.Card, a.Reason}
+}
@@ -1,16 +1,14 @@
 package main
 
 import (
-	"errors"
 	"fmt"
 	"go/format"
-	"reflect"
-	"strings"
 	"syscall/js"
 	"time"
 
 	"github.com/quasilyte/gophers-and-dragons/game"
 	"github.com/quasilyte/gophers-and-dragons/wasm/gamedata"
+	"github.com/quasilyte/gophers-and-dragons/wasm/program"
 	"github.com/quasilyte/gophers-and-dragons/wasm/sim"
 	"github.com/quasilyte/gophers-and-dragons/wasm/simstep"
 	"github.com/traefik/yaegi/interp"
@@ -22,6 +20,9 @@ func main() {
 	js.Global().Set("gofmt", js.FuncOf(gofmt))
 	js.Global().Set("evalGo", js.FuncOf(evalGo))
 	js.Global().Set("runSimulation", js.FuncOf(runSimulationJS))
+	js.Global().Set("runPuzzle", js.FuncOf(runPuzzleJS))
+	js.Global().Set("runCampaign", js.FuncOf(runCampaignJS))
+	js.Global().Set("runDuel", js.FuncOf(runDuelJS))
 	js.Global().Set("getCreepStats", js.FuncOf(getCreepStats))
 	js.Global().Set("getCardStats", js.FuncOf(getCardStats))
 
@@ -86,6 +87,28 @@ func getCardStats(this js.Value, inputs []js.Value) interface{} {
 	case "Parry":
 		typ = game.CardParry
 
+	case "ShieldBash":
+		typ = game.CardShieldBash
+	case "ChainLightning":
+		typ = game.CardChainLightning
+	case "Backstab":
+		typ = game.CardBackstab
+
+	case "Block":
+		typ = game.CardBlock
+	case "Drain":
+		typ = game.CardDrain
+	case "Poison":
+		typ = game.CardPoison
+	case "Focus":
+		typ = game.CardFocus
+	case "Meditate":
+		typ = game.CardMeditate
+	case "SmokeBomb":
+		typ = game.CardSmokeBomb
+	case "ScrollOfInsight":
+		typ = game.CardScrollOfInsight
+
 	default:
 		return nil
 	}
@@ -109,6 +132,16 @@ func getCreepStats(this js.Value, inputs []js.Value) interface{} {
 		typ = game.CreepFairy
 	case "Mummy":
 		typ = game.CreepMummy
+	case "Troll":
+		typ = game.CreepTroll
+	case "Golem":
+		typ = game.CreepGolem
+	case "Vampire":
+		typ = game.CreepVampire
+	case "Slime":
+		typ = game.CreepSlime
+	case "Ghost":
+		typ = game.CreepGhost
 	case "Dragon":
 		typ = game.CreepDragon
 	default:
@@ -118,75 +151,95 @@ func getCreepStats(this js.Value, inputs []js.Value) interface{} {
 	return creepStatsToJS(gamedata.GetCreepStats(typ))
 }
 
-func runSimulation(config js.Value, code string) (actions []simstep.Action, err error) {
-	i := interp.New(interp.Options{})
+func runSimulation(config js.Value, code, masterCode string) (actions []simstep.Action, err error) {
+	tactic, err := program.LoadTactic(code)
+	if err != nil {
+		return nil, err
+	}
+	var master *sim.DungeonMaster
+	if masterCode != "" {
+		master, err = program.LoadDungeonMaster(masterCode)
+		if err != nil {
+			return nil, fmt.Errorf("dungeon master: %v", err)
+		}
+	}
 
-	i.Use(map[string]map[string]reflect.Value{
-		"github.com/quasilyte/gophers-and-dragons/game": {
-			"State":          reflect.ValueOf((*game.State)(nil)),
-			"Avatar":         reflect.ValueOf((*game.Avatar)(nil)),
-			"AvatarStats":    reflect.ValueOf((*game.AvatarStats)(nil)),
-			"Card":           reflect.ValueOf((*game.Card)(nil)),
-			"CardStats":      reflect.ValueOf((*game.CardStats)(nil)),
-			"CardType":       reflect.ValueOf((*game.CardType)(nil)),
-			"Creep":          reflect.ValueOf((*game.Creep)(nil)),
-			"CreepStats":     reflect.ValueOf((*game.CreepStats)(nil)),
-			"CreepType":      reflect.ValueOf((*game.CreepType)(nil)),
-			"CreepTrait":     reflect.ValueOf((*game.CreepTrait)(nil)),
-			"CreepTraitList": reflect.ValueOf((*game.CreepTraitList)(nil)),
-			"IntRange":       reflect.ValueOf((*game.IntRange)(nil)),
-
-			"CreepCheepy": reflect.ValueOf(game.CreepCheepy),
-			"CreepImp":    reflect.ValueOf(game.CreepImp),
-			"CreepLion":   reflect.ValueOf(game.CreepLion),
-			"CreepFairy":  reflect.ValueOf(game.CreepFairy),
-			"CreepMummy":  reflect.ValueOf(game.CreepMummy),
-			"CreepDragon": reflect.ValueOf(game.CreepDragon),
-
-			"TraitCoward":        reflect.ValueOf(game.TraitCoward),
-			"TraitMagicImmunity": reflect.ValueOf(game.TraitMagicImmunity),
-			"TraitWeakToFire":    reflect.ValueOf(game.TraitWeakToFire),
-			"TraitSlow":          reflect.ValueOf(game.TraitSlow),
-			"TraitRanged":        reflect.ValueOf(game.TraitRanged),
-
-			"CardMagicArrow":  reflect.ValueOf(game.CardMagicArrow),
-			"CardAttack":      reflect.ValueOf(game.CardAttack),
-			"CardPowerAttack": reflect.ValueOf(game.CardPowerAttack),
-			"CardStun":        reflect.ValueOf(game.CardStun),
-			"CardFirebolt":    reflect.ValueOf(game.CardFirebolt),
-			"CardRetreat":     reflect.ValueOf(game.CardRetreat),
-			"CardRest":        reflect.ValueOf(game.CardRest),
-			"CardHeal":        reflect.ValueOf(game.CardHeal),
-			"CardParry":       reflect.ValueOf(game.CardParry),
+	seed := config.Get("seed")
+	simConfig := &sim.Config{
+		Rounds:   config.Get("rounds").Int(),
+		AvatarHP: config.Get("avatarHP").Int(),
+		AvatarMP: config.Get("avatarMP").Int(),
+		Class:    parseClass(config.Get("class")),
+		Draft:    config.Get("draft").Truthy(),
+		Leveling: config.Get("leveling").Truthy(),
+		Bosses:   config.Get("bosses").Truthy(),
+		Intents:  config.Get("intents").Truthy(),
+		MapMode:  config.Get("mapMode").Truthy(),
+		Endless:  config.Get("endless").Truthy(),
+
+		BossEvery: jsInt(config.Get("bossEvery"), 0),
+		BestScore: jsInt(config.Get("bestScore"), 0),
+		Scaling: sim.ScalingCurve{
+			Linear:    jsInt(config.Get("scalingLinear"), 0),
+			Quadratic: jsInt(config.Get("scalingQuadratic"), 0),
 		},
-	})
-	// i.Use(stdlib.Symbols)
+		Modifiers: parseModifiers(config.Get("modifiers")),
+
+		CombatRolls:    config.Get("combatRolls").Truthy(),
+		ExpansionCards: config.Get("expansionCards").Truthy(),
+		Combos:         config.Get("combos").Truthy(),
+		Potions:        config.Get("potions").Truthy(),
+		RiskyRetreat:   config.Get("riskyRetreat").Truthy(),
+		IllegalMoves:   parseIllegalMoves(config.Get("illegalMoves")),
+	}
+	if seed.Type() == js.TypeNumber {
+		simConfig.Seed = int64(seed.Int())
+	} else {
+		simConfig.Seed = time.Now().UnixNano()
+	}
 
-	if _, err := i.Eval(code); err != nil {
+	if master != nil {
+		return sim.RunDungeonMaster(simConfig, tactic, master), nil
+	}
+	return sim.RunTactic(simConfig, tactic), nil
+}
+
+func runPuzzle(config js.Value, code, scenarioJSON string) (actions []simstep.Action, err error) {
+	tactic, err := program.LoadTactic(code)
+	if err != nil {
 		return nil, err
 	}
+	scenario, err := sim.ParseScenario([]byte(scenarioJSON))
+	if err != nil {
+		return nil, fmt.Errorf("puzzle: %v", err)
+	}
 
-	pkg := inferPackage(code)
-	userFuncSym := "ChooseCard"
-	if pkg != "" {
-		userFuncSym = pkg + ".ChooseCard"
+	simConfig := &sim.Config{
+		AvatarHP: config.Get("avatarHP").Int(),
+		AvatarMP: config.Get("avatarMP").Int(),
 	}
+	return sim.RunPuzzle(simConfig, scenario, tactic), nil
+}
 
-	res, err := i.Eval(userFuncSym)
+func runCampaign(config js.Value, code, progressJSON string) (actions []simstep.Action, err error) {
+	tactic, err := program.LoadTactic(code)
 	if err != nil {
-		return nil, errors.New("can't find proper ChooseCard definition")
+		return nil, err
 	}
-
-	userFunc, ok := res.Interface().(func(game.State) game.CardType)
-	if !ok {
-		return nil, errors.New("can't find proper ChooseCard definition")
+	progress, err := sim.ParseCampaignProgress([]byte(progressJSON))
+	if err != nil {
+		return nil, fmt.Errorf("campaign progress: %v", err)
 	}
 
 	seed := config.Get("seed")
 	simConfig := &sim.Config{
-		Rounds:   config.Get("rounds").Int(),
 		AvatarHP: config.Get("avatarHP").Int(),
 		AvatarMP: config.Get("avatarMP").Int(),
+		Class:    parseClass(config.Get("class")),
+		Draft:    config.Get("draft").Truthy(),
+		Leveling: config.Get("leveling").Truthy(),
+
+		Modifiers: parseModifiers(config.Get("modifiers")),
 	}
 	if seed.Type() == js.TypeNumber {
 		simConfig.Seed = int64(seed.Int())
@@ -194,14 +247,75 @@ func runSimulation(config js.Value, code string) (actions []simstep.Action, err
 		simConfig.Seed = time.Now().UnixNano()
 	}
 
-	return sim.Run(simConfig, userFunc), nil
+	return sim.RunCampaign(simConfig, progress, tactic), nil
+}
+
+func runDuel(config js.Value, firstCode, secondCode string) (actions []simstep.Action, err error) {
+	first, err := program.LoadTactic(firstCode)
+	if err != nil {
+		return nil, fmt.Errorf("first duelist: %v", err)
+	}
+	second, err := program.LoadTactic(secondCode)
+	if err != nil {
+		return nil, fmt.Errorf("second duelist: %v", err)
+	}
+
+	seed := config.Get("seed")
+	duelConfig := &sim.DuelConfig{
+		AvatarHP:     config.Get("avatarHP").Int(),
+		AvatarMP:     config.Get("avatarMP").Int(),
+		Simultaneous: config.Get("simultaneous").Truthy(),
+		IllegalMoves: parseIllegalMoves(config.Get("illegalMoves")),
+	}
+	if seed.Type() == js.TypeNumber {
+		duelConfig.Seed = int64(seed.Int())
+	} else {
+		duelConfig.Seed = time.Now().UnixNano()
+	}
+
+	return sim.RunDuel(duelConfig, first, second), nil
 }
 
 func runSimulationJS(this js.Value, inputs []js.Value) interface{} {
 	config := inputs[0]
 	code := inputs[1].String()
+	masterCode := ""
+	if len(inputs) > 2 && inputs[2].Type() == js.TypeString {
+		masterCode = inputs[2].String()
+	}
+
+	actions, err := runSimulation(config, code, masterCode)
+	return actionsToJS(actions, err)
+}
+
+func runPuzzleJS(this js.Value, inputs []js.Value) interface{} {
+	config := inputs[0]
+	code := inputs[1].String()
+	scenarioJSON := inputs[2].String()
+
+	actions, err := runPuzzle(config, code, scenarioJSON)
+	return actionsToJS(actions, err)
+}
+
+func runCampaignJS(this js.Value, inputs []js.Value) interface{} {
+	config := inputs[0]
+	code := inputs[1].String()
+	progressJSON := inputs[2].String()
+
+	actions, err := runCampaign(config, code, progressJSON)
+	return actionsToJS(actions, err)
+}
+
+func runDuelJS(this js.Value, inputs []js.Value) interface{} {
+	config := inputs[0]
+	firstCode := inputs[1].String()
+	secondCode := inputs[2].String()
+
+	actions, err := runDuel(config, firstCode, secondCode)
+	return actionsToJS(actions, err)
+}
 
-	actions, err := runSimulation(config, code)
+func actionsToJS(actions []simstep.Action, err error) interface{} {
 	if err != nil {
 		return []interface{}{
 			(simstep.RedLog{Message: fmt.Sprintf("Error: %s", err.Error())}).Fields(),
@@ -215,17 +329,50 @@ func runSimulationJS(this js.Value, inputs []js.Value) interface{} {
 	return jsResult
 }
 
-func inferPackage(s string) string {
-	newline := strings.IndexByte(s, '\n')
-	if newline == -1 {
-		return ""
+func parseClass(v js.Value) game.AvatarClass {
+	if v.Type() != js.TypeString {
+		return game.ClassNone
 	}
-	line := s[:newline]
-	if !strings.HasPrefix(line, "package ") {
-		return ""
+	switch v.String() {
+	case "Warrior":
+		return game.ClassWarrior
+	case "Mage":
+		return game.ClassMage
+	case "Rogue":
+		return game.ClassRogue
+	default:
+		return game.ClassNone
 	}
-	packageName := line[len("package "):]
-	return packageName
+}
+
+// parseModifiers converts an array of modifier names.
+// Unknown names are ignored.
+func parseModifiers(v js.Value) []sim.Modifier {
+	if v.Type() != js.TypeObject {
+		return nil
+	}
+	var list []sim.Modifier
+	for i := 0; i < v.Length(); i++ {
+		if m, ok := sim.ParseModifier(v.Index(i).String()); ok {
+			list = append(list, m)
+		}
+	}
+	return list
+}
+
+func parseIllegalMoves(v js.Value) sim.IllegalMovePolicy {
+	if v.Type() != js.TypeString {
+		return sim.IllegalMoveSkip
+	}
+	policy, _ := sim.ParseIllegalMovePolicy(v.String())
+	return policy
+}
+
+func jsInt(v js.Value, defaultValue int) int {
+	if v.Type() != js.TypeNumber {
+		return defaultValue
+	}
+	return v.Int()
 }
 
 func creepStatsToJS(stats game.CreepStats) map[string]interface{} {
@@ -233,12 +380,17 @@ func creepStatsToJS(stats game.CreepStats) map[string]interface{} {
 	for _, x := range stats.Traits {
 		traits = append(traits, x.String())
 	}
+	resistances := map[string]interface{}{}
+	for e, percent := range stats.Resistances {
+		resistances[e.String()] = percent
+	}
 	return map[string]interface{}{
 		"maxHP":       stats.MaxHP,
 		"damage":      []interface{}{stats.Damage[0], stats.Damage[1]},
 		"scoreReward": stats.ScoreReward,
 		"cardsReward": stats.CardsReward,
 		"traits":      traits,
+		"resistances": resistances,
 	}
 }
 
@@ -249,5 +401,6 @@ func cardStatsToJS(stats game.CardStats) map[string]interface{} {
 		"isOffensive": stats.IsOffensive,
 		"power":       []interface{}{stats.Power[0], stats.Power[1]},
 		"effect":      stats.Effect,
+		"element":     stats.Element.String(),
 	}
 }
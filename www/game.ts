@@ -7,7 +7,10 @@ declare class LZString {
 declare function gominify(code: string): string;
 declare function gofmt(code: string): string;
 declare function evalGo(code: string): any;
-declare function runSimulation(config: any, code: string): any;
+declare function runSimulation(config: any, code: string, masterCode?: string): any;
+declare function runPuzzle(config: any, code: string, scenario: string): any;
+declare function runCampaign(config: any, code: string, progress: string): any;
+declare function runDuel(config: any, firstCode: string, secondCode: string): any;
 declare function getCreepStats(name: string): any;
 declare function getCardStats(name: string): any;
 
@@ -67,6 +70,7 @@ export function main() {
         'details': document.getElementById('hover_details'),
         'tactics': document.getElementById('tactics_editor') as HTMLTextAreaElement,
         'settings': document.getElementById('settings_editor') as HTMLTextAreaElement,
+        'opponent': document.getElementById('opponent_editor') as HTMLTextAreaElement,
         'button': {
             'run': document.getElementById('button_run') as HTMLInputElement,
             'pause': document.getElementById('button_pause') as HTMLInputElement,
@@ -81,11 +85,16 @@ export function main() {
             'pic': document.getElementById('avatar_status_pic') as HTMLImageElement, 
             'hp': document.getElementById('avatar_status_hp'),
             'mp': document.getElementById('avatar_status_mp'),
+            'level': document.getElementById('avatar_status_level'),
+            'effects': document.getElementById('avatar_status_effects'),
+            'potions': document.getElementById('avatar_status_potions'),
         },
         'creep': {
             'pic': document.getElementById('creep_status_pic') as HTMLImageElement,
             'name': document.getElementById('creep_status_name'),
             'hp': document.getElementById('creep_status_hp'),
+            'intent': document.getElementById('creep_status_intent'),
+            'effects': document.getElementById('creep_status_effects'),
         },
         'nextCreep': {
             'pic': document.getElementById('next_creep_status_pic') as HTMLImageElement,
@@ -97,6 +106,8 @@ export function main() {
             'turn': document.getElementById('status_turn'),
             'round': document.getElementById('status_round'),
         },
+        'rounds': document.getElementById('status_rounds'),
+        'best': document.getElementById('status_best'),
     };
     const cardElements = {
         'PowerAttack': document.getElementById('card_power_attack'),
@@ -104,6 +115,22 @@ export function main() {
         'Stun': document.getElementById('card_stun'),
         'Heal': document.getElementById('card_heal'),
         'Parry': document.getElementById('card_parry'),
+        'ShieldBash': document.getElementById('card_shield_bash'),
+        'ChainLightning': document.getElementById('card_chain_lightning'),
+        'Backstab': document.getElementById('card_backstab'),
+        'Block': document.getElementById('card_block'),
+        'Drain': document.getElementById('card_drain'),
+        'Poison': document.getElementById('card_poison'),
+        'Focus': document.getElementById('card_focus'),
+        'Meditate': document.getElementById('card_meditate'),
+        'SmokeBomb': document.getElementById('card_smoke_bomb'),
+        'ScrollOfInsight': document.getElementById('card_scroll_of_insight'),
+    };
+    const infiniteCardElements = {
+        'Attack': document.getElementById('card_attack'),
+        'MagicArrow': document.getElementById('card_magic_arrow'),
+        'Retreat': document.getElementById('card_retreat'),
+        'Rest': document.getElementById('card_rest'),
     };
 
     const urlParams = new URLSearchParams(window.location.search);
@@ -112,10 +139,36 @@ export function main() {
         avatarHP: 40,
         avatarMP: 20,
         seed: null,
+        class: '',
+        draft: false,
+        leveling: false,
+        duel: false,
+        simultaneous: false,
+        dungeonMaster: false,
+        puzzle: '',
+        campaign: false,
+        modifiers: [],
+        hardcoreSeed: -1,
+        player: '',
+        playerKey: '',
+        bosses: false,
+        intents: false,
+        combatRolls: false,
+        expansionCards: false,
+        combos: false,
+        potions: false,
+        riskyRetreat: false,
+        illegalMoves: 'skip',
+        mapMode: false,
+        endless: false,
+        bossEvery: 10,
+        scalingLinear: 10,
+        scalingQuadratic: 0,
     }
 
     const NUM_ROUNDS = 10;
     const AVATAR_ID = urlParams.get('avatar') || rand(5);
+    const BEST_ENDLESS_SCORE_KEY = 'bestEndlessScore';
 
     const cardDescriptions = {
         'Attack': 'Simple offensive action',
@@ -127,16 +180,47 @@ export function main() {
         'Rest': 'Heal minor wounds by resting',
         'Heal': 'Heal wounds using magic',
         'Parry': 'Reflect a melee (non-ranged) back to the enemy',
+        'ShieldBash': 'Warrior-only attack that stuns the enemy for 1 turn',
+        'ChainLightning': 'Mage-only spell that also destroys a summoned minion',
+        'Backstab': 'Rogue-only attack that is not noticed by cowards',
+        'Block': 'Absorb some damage from the next hit',
+        'Drain': 'Deal magic damage and recover as much HP',
+        'Poison': 'Deal poison damage at the end of every turn',
+        'Focus': 'Double the damage of the next attack',
+        'Meditate': 'Recover some MP',
+        'SmokeBomb': 'Retreat without taking a hit',
+        'ScrollOfInsight': 'Reveal two creeps after the next one',
     };
 
     let paused = false;
+    let currentPaths: string[] = [];
     let currentSimulationInterval = null;
     let currentSimulationPlayer: SimulationPlayer = null;
+    let statusEffects = {'avatar': {}, 'creep': {}};
 
     function setCreep(name: string, hp: number) {
         elements.creep.pic.src = `img/creep/${name}.png`;
         elements.creep.name.innerText = name;
         elements.creep.hp.innerText = hp.toString();
+        elements.creep.intent.innerText = 'Attack';
+        setStatusEffect('creep', '', 0);
+    }
+
+    // setStatusEffect updates the target status; empty name clears all statuses.
+    function setStatusEffect(target: string, name: string, value: number) {
+        if (name === '') {
+            statusEffects[target] = {};
+        } else if (value == 0) {
+            delete statusEffects[target][name];
+        } else {
+            statusEffects[target][name] = value;
+        }
+        let parts = [];
+        for (let key in statusEffects[target]) {
+            // Focus is a flag-like status, it has no meaningful value.
+            parts.push(key === 'Focus' ? key : `${key} ${statusEffects[target][key]}`);
+        }
+        elements[target].effects.innerText = parts.join(', ');
     }
 
     function setNextCreep(name: string, hp: number) {
@@ -185,18 +269,35 @@ export function main() {
         for (const key in cardElements) {
             cardElements[key].innerText = '0';
         }
+        for (const key in infiniteCardElements) {
+            infiniteCardElements[key].innerText = '∞';
+        }
         // Set status counters to 0.
         for (const key in elements.status) {
             elements.status[key].innerText = '0';
         }
         elements.status.score.classList.remove('text-green');
+        elements.rounds.innerText = gameSettings.endless ? '∞' : `${NUM_ROUNDS}`;
+        elements.best.innerText = localStorage.getItem(BEST_ENDLESS_SCORE_KEY) || '0';
         // Reset hero.
         elements.avatar.hp.innerText = `${gameSettings.avatarHP}`;
         elements.avatar.mp.innerText = `${gameSettings.avatarMP}`;
+        elements.avatar.level.innerText = '1';
         elements.avatar.pic.src = `img/avatar/avatar${AVATAR_ID}.png`;
+        setStatusEffect('avatar', '', 0);
+        setStatusEffect('creep', '', 0);
+        elements.avatar.potions.innerText = '';
         // Set the initial creeps.
         setCreep('Cheepy', getCreepStats('Cheepy').maxHP);
         setNextCreep('Imp', getCreepStats('Imp').maxHP);
+        if (gameSettings.duel) {
+            // The opponent avatar is rendered instead of the creep.
+            elements.creep.pic.src = `img/avatar/avatar${(parseInt(`${AVATAR_ID}`, 10) + 1) % 4}.png`;
+            elements.creep.name.innerText = 'Opponent';
+            elements.creep.hp.innerText = `${gameSettings.avatarHP}`;
+            elements.creep.intent.innerText = `${gameSettings.avatarMP} MP`;
+            setNextCreep('None', 0);
+        }
         // Clear the game logs.
         elements.log.innerText = '';
     }
@@ -212,6 +313,9 @@ export function main() {
         if (stats.traits.length != 0) {
             details += `Traits: ${stats.traits.join(', ')}<br>`;
         }
+        for (let element in stats.resistances) {
+            details += `${element} resistance: ${stats.resistances[element]}%<br>`;
+        }
         elements.details.innerHTML = details;
     }
 
@@ -229,6 +333,9 @@ export function main() {
                 `${stats.power[0]}-${stats.power[1]}`;
             details += `Power: ${power} (${stats.effect})<br>`
         }
+        if (stats.isOffensive && stats.effect.indexOf('damage') != -1) {
+            details += `Element: ${stats.element}<br>`;
+        }
         elements.details.innerHTML = details;
     }
 
@@ -314,6 +421,37 @@ export function main() {
                 gameSettings.avatarMP = x.avatarMP;
             }
             gameSettings.seed = x.seed || null;
+            gameSettings.class = x.class || '';
+            gameSettings.draft = x.draft || false;
+            gameSettings.leveling = x.leveling || false;
+            gameSettings.duel = x.duel || false;
+            gameSettings.simultaneous = x.simultaneous || false;
+            gameSettings.dungeonMaster = x.dungeonMaster || false;
+            gameSettings.puzzle = x.puzzle || '';
+            gameSettings.campaign = x.campaign || false;
+            gameSettings.modifiers = x.modifiers || [];
+            gameSettings.hardcoreSeed = (x.hardcoreSeed === undefined) ? -1 : x.hardcoreSeed;
+            gameSettings.player = x.player || '';
+            gameSettings.playerKey = x.playerKey || '';
+            gameSettings.bosses = x.bosses || false;
+            gameSettings.intents = x.intents || false;
+            gameSettings.combatRolls = x.combatRolls || false;
+            gameSettings.expansionCards = x.expansionCards || false;
+            gameSettings.combos = x.combos || false;
+            gameSettings.potions = x.potions || false;
+            gameSettings.riskyRetreat = x.riskyRetreat || false;
+            gameSettings.illegalMoves = x.illegalMoves || 'skip';
+            gameSettings.mapMode = x.mapMode || false;
+            gameSettings.endless = x.endless || false;
+            if (typeof x.bossEvery === 'number') {
+                gameSettings.bossEvery = x.bossEvery;
+            }
+            if (typeof x.scalingLinear === 'number') {
+                gameSettings.scalingLinear = x.scalingLinear;
+            }
+            if (typeof x.scalingQuadratic === 'number') {
+                gameSettings.scalingQuadratic = x.scalingQuadratic;
+            }
         } catch (e) {
             console.error("bad settings: " + e)
         }
@@ -333,6 +471,15 @@ export function main() {
 
         resetPage();
 
+        // Some creeps have no picture yet.
+        for (let pic of [elements.creep.pic, elements.nextCreep.pic]) {
+            pic.onerror = function() {
+                if (pic.src.indexOf('img/creep/None.png') == -1) {
+                    pic.src = 'img/creep/None.png';
+                }
+            };
+        }
+
         elements.creep.pic.onmouseenter = function(e) {
             let currentCreep = elements.creep.name.innerText;
             if (currentCreep === 'None') {
@@ -352,13 +499,9 @@ export function main() {
 
         elements.tab.onchange = function(e) {
             let selected = elements.tab.options[elements.tab.selectedIndex].value;
-            if (selected === 'tab_tactics') {
-                elements.tactics.style.display = '';
-                elements.settings.style.display = 'none';
-            } else if (selected === 'tab_settings') {
-                elements.tactics.style.display = 'none';
-                elements.settings.style.display = '';
-            }
+            elements.tactics.style.display = (selected === 'tab_tactics') ? '' : 'none';
+            elements.settings.style.display = (selected === 'tab_settings') ? '' : 'none';
+            elements.opponent.style.display = (selected === 'tab_opponent') ? '' : 'none';
         };
 
         let cardLabels = document.getElementsByClassName('card');
@@ -398,14 +541,71 @@ export function main() {
             config["avatarMP"] = gameSettings.avatarMP;
             config["rounds"] = NUM_ROUNDS;
             config["seed"] = gameSettings.seed;
+            config["class"] = gameSettings.class;
+            config["draft"] = gameSettings.draft;
+            config["leveling"] = gameSettings.leveling;
+            config["bosses"] = gameSettings.bosses;
+            config["intents"] = gameSettings.intents;
+            config["combatRolls"] = gameSettings.combatRolls;
+            config["expansionCards"] = gameSettings.expansionCards;
+            config["combos"] = gameSettings.combos;
+            config["potions"] = gameSettings.potions;
+            config["riskyRetreat"] = gameSettings.riskyRetreat;
+            config["illegalMoves"] = gameSettings.illegalMoves;
+            config["mapMode"] = gameSettings.mapMode;
+            config["endless"] = gameSettings.endless;
+            config["bossEvery"] = gameSettings.bossEvery;
+            config["scalingLinear"] = gameSettings.scalingLinear;
+            config["scalingQuadratic"] = gameSettings.scalingQuadratic;
+            config["bestScore"] = parseInt(localStorage.getItem(BEST_ENDLESS_SCORE_KEY) || '0', 10);
+            config["modifiers"] = gameSettings.modifiers;
             let code = elements.tactics.value;
-            let actions = runSimulation(config, code);
+            if (gameSettings.hardcoreSeed >= 0) {
+                // Hardcore attempts are evaluated by the server.
+                // Every attempt consumes a single-use token.
+                let failed = (msg: string) => ({actions: [['redLog', msg]]});
+                let tokenReq = {seedID: gameSettings.hardcoreSeed};
+                let auth = 'Basic ' + btoa(gameSettings.player + ':' + gameSettings.playerKey);
+                fetch('api/hardcore/token', {method: 'POST', headers: {'Authorization': auth}, body: JSON.stringify(tokenReq)})
+                    .then(resp => resp.ok ? resp.json() : resp.text().then(msg => ({error: msg})))
+                    .then(result => {
+                        if (result.error) {
+                            return failed(result.error);
+                        }
+                        let req = {seedID: gameSettings.hardcoreSeed, token: result.token, code: code};
+                        return fetch('api/hardcore/attempt', {method: 'POST', body: JSON.stringify(req)})
+                            .then(resp => resp.ok ? resp.json() : resp.text().then(failed));
+                    })
+                    .then(result => startSimulation(result.actions));
+                return;
+            }
+            if (gameSettings.puzzle) {
+                fetch(`puzzles/${gameSettings.puzzle}.json`)
+                    .then(resp => resp.text())
+                    .then(scenario => startSimulation(runPuzzle(config, code, scenario)));
+                return;
+            }
+            let actions = null;
+            if (gameSettings.campaign) {
+                actions = runCampaign(config, code, window.localStorage.getItem('campaignProgress') || '');
+            } else if (gameSettings.duel) {
+                config["simultaneous"] = gameSettings.simultaneous;
+                actions = runDuel(config, code, elements.opponent.value);
+            } else if (gameSettings.dungeonMaster) {
+                actions = runSimulation(config, code, elements.opponent.value);
+            } else {
+                actions = runSimulation(config, code);
+            }
+            startSimulation(actions);
+        };
+
+        function startSimulation(actions: any) {
             let speed = parseInt(elements.speed.options[elements.speed.selectedIndex].value, 10);
             currentSimulationPlayer = new SimulationPlayer(actions);
             console.log('starting applyActions with speed=%d', speed);
             console.log('actions:', actions);
             applyActions(speed, currentSimulationPlayer);
-        };
+        }
 
         document.addEventListener('keyup', function(e) {
             let textareaFocused = (elements.tactics === document.activeElement);
@@ -415,10 +615,11 @@ export function main() {
             }
         });
         document.addEventListener('keydown', function(e) {
-            let textareaFocused = (elements.tactics === document.activeElement);
-            if (e.code === 'Tab' && textareaFocused) {
-                e.preventDefault();
-                insertText(elements.tactics, '    ');
+            for (let editor of [elements.tactics, elements.opponent]) {
+                if (e.code === 'Tab' && editor === document.activeElement) {
+                    e.preventDefault();
+                    insertText(editor, '    ');
+                }
             }
         });
 
@@ -443,6 +644,14 @@ export function main() {
         defeat: function() {
             elements.avatar.pic.src = `img/dead_avatar/avatar${AVATAR_ID}.png`;
         },
+        endlessResult: function(round: number, score: number, best: number) {
+            // Endless mode score is tracked as a high-water mark.
+            localStorage.setItem(BEST_ENDLESS_SCORE_KEY, `${best}`);
+            elements.best.innerText = `${best}`;
+            if (score == best && score > 0) {
+                elements.status.score.classList.add('text-green');
+            }
+        },
         log: function(message: string) {
             elements.log.innerHTML += `${message}<br>`;
             elements.log.scrollTop = elements.log.scrollHeight;
@@ -455,13 +664,81 @@ export function main() {
             elements.log.innerHTML += `<span class="text-green">${message}</span><br>`;
             elements.log.scrollTop = elements.log.scrollHeight;
         },
+        missLog: function(message: string) {
+            elements.log.innerHTML += `<span class="text-gray">${message}</span><br>`;
+            elements.log.scrollTop = elements.log.scrollHeight;
+        },
+        critLog: function(message: string) {
+            elements.log.innerHTML += `<span class="text-orange">${message}</span><br>`;
+            elements.log.scrollTop = elements.log.scrollHeight;
+        },
         changeCardCount: function(name: string, delta: number) {
             updateElementText(cardElements[name], delta);
         },
+        updateDuelHP: function(side: number, delta: number) {
+            updateElementText(side == 0 ? elements.avatar.hp : elements.creep.hp, delta);
+        },
+        updateDuelMP: function(side: number, delta: number) {
+            if (side == 0) {
+                updateElementText(elements.avatar.mp, delta);
+            } else {
+                let mp = parseInt(elements.creep.intent.innerText, 10) + delta;
+                elements.creep.intent.innerText = `${mp} MP`;
+            }
+        },
+        changeDuelCardCount: function(side: number, name: string, delta: number) {
+            // Only the first duelist cards are displayed.
+            if (side == 0) {
+                updateElementText(cardElements[name], delta);
+            }
+        },
+        saveProgress: function(data: string) {
+            window.localStorage.setItem('campaignProgress', data);
+        },
+        puzzleResult: function(solved: boolean) {
+            if (solved) {
+                elements.status.score.classList.add('text-green');
+            } else {
+                elements.avatar.pic.src = `img/dead_avatar/avatar${AVATAR_ID}.png`;
+            }
+        },
+        duelResult: function(winner: number) {
+            if (winner == 0) {
+                elements.creep.pic.src = 'img/creep/None.png';
+                elements.status.score.classList.add('text-green');
+            } else if (winner == 1) {
+                elements.avatar.pic.src = `img/dead_avatar/avatar${AVATAR_ID}.png`;
+            }
+        },
+        levelUp: function(level: number) {
+            elements.avatar.level.innerText = `${level}`;
+        },
+        setCardCount: function(name: string, count: number) {
+            cardElements[name].innerText = (count == -1) ? '∞' : `${count}`;
+        },
+        dropCard: function(name: string) {
+            infiniteCardElements[name].innerText = '0';
+        },
+        meta: function(key: string, value: string) {
+            handlers.log(`<span class="text-violet">${key}: ${value}</span>`);
+        },
         nextRound: function() {
-            if (parseInt(elements.status.round.innerText, 10) != NUM_ROUNDS) {
+            if (gameSettings.endless || parseInt(elements.status.round.innerText, 10) != NUM_ROUNDS) {
                 updateElementText(elements.status.round, 1);
             }
+            setStatusEffect('creep', '', 0);
+        },
+        setStatus: function(target: string, name: string, value: number) {
+            setStatusEffect(target, name, value);
+        },
+        setForecast: function(names: string) {
+            handlers.log(`<span class="text-violet">Forecast: ${names}</span>`);
+        },
+        illegalMove: function(card: string, reason: string) {
+            // The illegal move is already explained by the redLog that follows it.
+        },
+        setPotions: function(names: string) {
+            elements.avatar.potions.innerText = (names === '') ? '' : `Potions: ${names}`;
         },
         updateScore: function(delta: number) {
             updateElementText(elements.status.score, delta);
@@ -481,6 +758,17 @@ export function main() {
         setNextCreep: function(name: string, hp: number) {
             setNextCreep(name, hp);
         },
+        setCreepIntent: function(name: string) {
+            elements.creep.intent.innerText = name;
+        },
+        showPaths: function(options: string[]) {
+            currentPaths = options;
+            let items = options.map((o, i) => `[${i}] ${o}`);
+            handlers.log(`<span class="text-violet">Paths: ${items.join(', ')}</span>`);
+        },
+        selectPath: function(index: number) {
+            handlers.log(`<span class="text-violet">Selected path [${index}] ${currentPaths[index]}</span>`);
+        },
     };
 
     function applyActions(interval: number, player: SimulationPlayer) {